@@ -0,0 +1,98 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache/arc"
+	lru2 "MisakaCache/src/misakacache/lru"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestARC_GetValue(t *testing.T) {
+	cache := arc.NewARC(int64(100), nil)
+	cache.SetValue("key1", String("1234"))
+	if value, isOk := cache.GetValue("key1"); !isOk || value != String("1234") {
+		t.Fatalf("cache hit key1=1234 failed, value actually is %s", value)
+	}
+	if _, isOk := cache.GetValue("key2"); isOk {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestARC_OnEntryDeleted(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value lru2.Value) {
+		keys = append(keys, key)
+	}
+	cache := arc.NewARC(int64(20), callback)
+	cache.SetValue("key1", String("value1"))
+	cache.SetValue("key2", String("value2"))
+	cache.SetValue("key3", String("value3"))
+	cache.SetValue("key4", String("value3"))
+	expected := []string{"key1", "key2"}
+
+	if !reflect.DeepEqual(expected, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, now keys are %s", expected, keys)
+	}
+	if cache.BytesUsed() > 20 || cache.GetARCEntryNumber() != 2 {
+		t.Fatalf("memory accounting broken, used %d with %d entries", cache.BytesUsed(), cache.GetARCEntryNumber())
+	}
+}
+
+func TestARC_GhostHit(t *testing.T) {
+	cache := arc.NewARC(int64(30), nil)
+	cache.SetValue("key1", String("value1"))
+	cache.GetValue("key1") // key1进入T2
+	cache.SetValue("key2", String("value2"))
+	cache.SetValue("key3", String("value3"))
+	cache.SetValue("key4", String("value4")) // key2从T1淘汰 进入B1
+	if _, isOk := cache.GetValue("key2"); isOk {
+		t.Fatalf("ghost entry key2 should not be hit")
+	}
+	cache.SetValue("key2", String("value2")) // B1幽灵命中 p增大
+	if cache.GetTarget() == 0 {
+		t.Fatalf("ghost hit in B1 should increase target")
+	}
+	if value, isOk := cache.GetValue("key2"); !isOk || value != String("value2") {
+		t.Fatalf("revived key2 should be hit")
+	}
+}
+
+// hitRatio 在给定的访问序列上回放 未命中时写入缓存 返回命中率
+func hitRatio(cache interface {
+	GetValue(key string) (lru2.Value, bool)
+	SetValue(key string, value lru2.Value)
+}, trace []string) float64 {
+	hits := 0
+	for _, key := range trace {
+		if _, isOk := cache.GetValue(key); isOk {
+			hits++
+		} else {
+			cache.SetValue(key, String("v"))
+		}
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+func TestARC_ScanResistance(t *testing.T) {
+	// 10个热点键每轮访问两次 之后是30个只访问一次的扫描键
+	trace := make([]string, 0)
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 2; i++ {
+			for hot := 0; hot < 10; hot++ {
+				trace = append(trace, "hot"+strconv.Itoa(hot))
+			}
+		}
+		for scan := 0; scan < 30; scan++ {
+			trace = append(trace, "scan"+strconv.Itoa(round)+"-"+strconv.Itoa(scan))
+		}
+	}
+
+	capacity := int64(20 * len("scan99-29v")) // 大约能放下20条缓存
+	lruRatio := hitRatio(lru2.NewLRU(capacity, nil), trace)
+	arcRatio := hitRatio(arc.NewARC(capacity, nil), trace)
+	t.Logf("LRU hit ratio %.3f, ARC hit ratio %.3f", lruRatio, arcRatio)
+	if arcRatio <= lruRatio {
+		t.Fatalf("ARC should beat LRU on scan plus hot set, LRU %.3f ARC %.3f", lruRatio, arcRatio)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchPeer 测试用的远程节点 所有以remote开头的key都属于它
+type batchPeer struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+func (p *batchPeer) PickPeer(key string) (misakacache.PeerCacheValueGetter, bool) {
+	return p, strings.HasPrefix(key, "remote")
+}
+
+func (p *batchPeer) GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	out.Value = []byte("peer:" + in.GetKey())
+	return nil
+}
+
+func (p *batchPeer) GetManyFromPeer(ctx context.Context, in *pb.BatchRequest, out *pb.BatchResponse) error {
+	p.mu.Lock()
+	p.batches = append(p.batches, in.GetKeys())
+	p.mu.Unlock()
+	for _, key := range in.GetKeys() {
+		out.Values = append(out.Values, &pb.KeyValue{Key: key, Value: []byte("peer:" + key)})
+	}
+	return nil
+}
+
+func TestGroup_GetMany(t *testing.T) {
+	var batches [][]string
+	group, _ := misakacache.NewRegistry().NewGroup("batch", misakacache.BatchGetterFunc(
+		func(ctx context.Context, keys []string) (map[string][]byte, error) {
+			batches = append(batches, keys)
+			values := make(map[string][]byte)
+			for _, key := range keys {
+				if key != "unknown" {
+					values[key] = []byte("db:" + key)
+				}
+			}
+			return values, nil
+		}), misakacache.WithCacheBytes(2<<10))
+	peer := &batchPeer{}
+	group.RegisterPeers(peer)
+
+	ctx := context.Background()
+	group.Get(ctx, "Tom") // 预先缓存一个key
+
+	keys := []string{"Tom", "Jack", "Jack", "Sam", "unknown", "remote1", "remote2", "remote1"}
+	views, err := group.GetMany(ctx, keys)
+	if err != nil {
+		t.Fatalf("failed to get many, err %v", err)
+	}
+	want := map[string]string{
+		"Tom": "db:Tom", "Jack": "db:Jack", "Sam": "db:Sam",
+		"remote1": "peer:remote1", "remote2": "peer:remote2",
+	}
+	if len(views) != len(want) {
+		t.Fatalf("expected %d values, got %d", len(want), len(views))
+	}
+	for key, value := range want {
+		if views[key].ToString() != value {
+			t.Fatalf("%s: expected %s, got %s", key, value, views[key].ToString())
+		}
+	}
+
+	// Tom的单独加载算一次 其余本地key合并成一次批量加载 并且重复的key只加载一次
+	if len(batches) != 2 || len(batches[1]) != 3 {
+		t.Fatalf("local misses should be loaded in one batch, got %v", batches)
+	}
+	if len(peer.batches) != 1 || len(peer.batches[0]) != 2 {
+		t.Fatalf("remote keys should be sent in one request, got %v", peer.batches)
+	}
+
+	// 第二次全部命中本地缓存 不再加载
+	group.GetMany(ctx, []string{"Jack", "Sam"})
+	if len(batches) != 2 {
+		t.Fatalf("cached keys should not be loaded again, got %v", batches)
+	}
+}
+
+func TestGroup_CoalesceMisses(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	group, _ := misakacache.NewRegistry().NewGroup("coalesce", misakacache.BatchGetterFunc(
+		func(ctx context.Context, keys []string) (map[string][]byte, error) {
+			mu.Lock()
+			batches = append(batches, keys)
+			mu.Unlock()
+			values := make(map[string][]byte)
+			for _, key := range keys {
+				if key != "unknown" {
+					values[key] = []byte("db:" + key)
+				}
+			}
+			return values, nil
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithBatchWindow(20*time.Millisecond, 0))
+
+	keys := []string{"Tom", "Jack", "Sam", "Tom", "unknown"}
+	errs := make([]error, len(keys))
+	views := make([]misakacache.ByteView, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			views[i], errs[i] = group.Get(context.Background(), key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	if len(batches) != 1 || len(batches[0]) != 4 {
+		t.Fatalf("concurrent misses should be merged into one batch of distinct keys, got %v", batches)
+	}
+	for i, key := range keys {
+		if key == "unknown" {
+			if !errors.Is(errs[i], misakacache.ErrNotFound) {
+				t.Fatalf("unknown should return ErrNotFound, got %v", errs[i])
+			}
+			continue
+		}
+		if errs[i] != nil || views[i].ToString() != "db:"+key {
+			t.Fatalf("%s: got %s err %v", key, views[i].ToString(), errs[i])
+		}
+	}
+}
+
+// ttlBatchGetter 测试用的getter 同时实现了Getter、TTLGetter和BatchGetter 记录每种方法的调用次数
+type ttlBatchGetter struct {
+	gets, batches atomic.Int32
+}
+
+func (g *ttlBatchGetter) Get(ctx context.Context, key string) ([]byte, error) {
+	bytes, _, err := g.GetWithTTL(ctx, key)
+	return bytes, err
+}
+
+func (g *ttlBatchGetter) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	g.gets.Add(1)
+	return []byte("db:" + key), 10 * time.Millisecond, nil
+}
+
+func (g *ttlBatchGetter) GetBatch(ctx context.Context, keys []string) (map[string][]byte, error) {
+	g.batches.Add(1)
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		values[key] = []byte("db:" + key)
+	}
+	return values, nil
+}
+
+func TestGroup_BatchWindowOptIn(t *testing.T) {
+	ctx := context.Background()
+	getter := &ttlBatchGetter{}
+	group, _ := misakacache.NewRegistry().NewGroup("batch-default", misakacache.BatchGetterFunc(getter.GetBatch))
+	if group.Config().BatchWindow != 0 {
+		t.Fatalf("misses should not be coalesced by default, got window %v", group.Config().BatchWindow)
+	}
+	if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "db:Tom" {
+		t.Fatalf("failed to get Tom, got %s err %v", view.ToString(), err)
+	}
+
+	// getter同时实现了TTLGetter时 即使设置了合并窗口也要使用每个key自己的存活时间
+	getter = &ttlBatchGetter{}
+	group, _ = misakacache.NewRegistry().NewGroup("batch-ttl", getter, misakacache.WithBatchWindow(20*time.Millisecond, 0))
+	group.Get(ctx, "Tom")
+	if getter.gets.Load() != 1 || getter.batches.Load() != 0 {
+		t.Fatalf("TTLGetter should be used for single misses, got %d gets %d batches", getter.gets.Load(), getter.batches.Load())
+	}
+	time.Sleep(20 * time.Millisecond)
+	group.Get(ctx, "Tom")
+	if getter.gets.Load() != 2 {
+		t.Fatalf("per-key ttl should be respected, got %d gets", getter.gets.Load())
+	}
+}
+
+func TestGroup_GetManyDedupAndChunks(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	entered, release := make(chan struct{}), make(chan struct{})
+	group, _ := misakacache.NewRegistry().NewGroup("batch-dedup", misakacache.BatchGetterFunc(
+		func(ctx context.Context, keys []string) (map[string][]byte, error) {
+			mu.Lock()
+			batches = append(batches, keys)
+			mu.Unlock()
+			if keys[0] == "Tom" { // 第一次加载Tom时卡住 让GetMany在它完成前到达
+				close(entered)
+				<-release
+			}
+			values := make(map[string][]byte)
+			for _, key := range keys {
+				values[key] = []byte("db:" + key)
+			}
+			return values, nil
+		}), misakacache.WithBatchWindow(0, 2))
+
+	ctx := context.Background()
+	go group.Get(ctx, "Tom")
+	<-entered
+	time.AfterFunc(20*time.Millisecond, func() { close(release) })
+	views, err := group.GetMany(ctx, []string{"Tom", "Jack", "Sam", "Bob"})
+	if err != nil || len(views) != 4 || views["Tom"].ToString() != "db:Tom" {
+		t.Fatalf("failed to get many, got %v err %v", views, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Tom由Get加载 GetMany等待它的结果 其余三个key按每批最多两个分成两批
+	if len(batches) != 3 || len(batches[1])+len(batches[2]) != 3 || len(batches[1]) > 2 || len(batches[2]) > 2 {
+		t.Fatalf("in-flight key should not be loaded again and batches should be split, got %v", batches)
+	}
+	if stats := group.Stats(); stats.LocalLoads != 4 {
+		t.Fatalf("local loads should be counted per key, got %d", stats.LocalLoads)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyPeer 测试用的远程节点 down为true时返回ErrUnavailable key为unknown时返回ErrNotFound
+type flakyPeer struct {
+	name     string
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func (p *flakyPeer) GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	p.requests.Add(1)
+	if p.down.Load() {
+		return fmt.Errorf("%s is down: %w", p.name, misakacache.ErrUnavailable)
+	}
+	if in.GetKey() == "unknown" {
+		return fmt.Errorf("%s not exist: %w", in.GetKey(), misakacache.ErrNotFound)
+	}
+	out.Value = []byte(p.name + ":" + in.GetKey())
+	return nil
+}
+
+// ringPicker 测试用的PeerPicker 所有key都属于第一个节点 之后依次是环上的其他节点
+type ringPicker []*flakyPeer
+
+func (r ringPicker) PickPeer(key string) (misakacache.PeerCacheValueGetter, bool) {
+	return r[0], true
+}
+
+func (r ringPicker) PickPeers(key string, n int) []misakacache.PeerCacheValueGetter {
+	var peers []misakacache.PeerCacheValueGetter
+	for i := 0; i < n && i < len(r); i++ {
+		peers = append(peers, r[i])
+	}
+	return peers
+}
+
+// listPicker 测试用的PeerPicker 实现了PeerLister 所有key都属于第一个节点
+type listPicker struct {
+	mu      sync.Mutex
+	peers   []*flakyPeer
+	version uint64
+}
+
+func (l *listPicker) PickPeer(key string) (misakacache.PeerCacheValueGetter, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.peers) == 0 {
+		return nil, false
+	}
+	return l.peers[0], true
+}
+
+func (l *listPicker) Peers() []misakacache.PeerCacheValueGetter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	peers := make([]misakacache.PeerCacheValueGetter, 0, len(l.peers))
+	for _, peer := range l.peers {
+		peers = append(peers, peer)
+	}
+	return peers
+}
+
+func (l *listPicker) PeersVersion() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.version
+}
+
+func (l *listPicker) set(peers ...*flakyPeer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.peers = peers
+	l.version++
+}
+
+// newBreakerGroup 在单独的Registry中创建Group 本地加载的值以local:开头
+func newBreakerGroup(name string, picker misakacache.PeerPicker, opts ...misakacache.GroupOption) *misakacache.Group {
+	group, _ := misakacache.NewRegistry().NewGroup(name, misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte("local:" + key), nil
+	}), opts...)
+	group.RegisterPeers(picker)
+	return group
+}
+
+func TestGroup_CircuitBreaker(t *testing.T) {
+	peer := &flakyPeer{name: "peer"}
+	peer.down.Store(true)
+	group := newBreakerGroup("breaker", ringPicker{peer}, misakacache.WithCircuitBreaker(misakacache.BreakerConfig{
+		MinRequests: 4,
+		ErrorRate:   0.5,
+		OpenTimeout: 50 * time.Millisecond,
+	}))
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ { // 每个key都不同 避免命中缓存
+		key := fmt.Sprintf("key%d", i)
+		if view, err := group.Get(ctx, key); err != nil || view.ToString() != "local:"+key {
+			t.Fatalf("failed peer should fall back to local, got %s err %v", view.ToString(), err)
+		}
+	}
+	if state, _ := group.PeerBreakerState("key"); state != misakacache.BreakerOpen {
+		t.Fatalf("breaker should be open after too many failures, got %v", state)
+	}
+
+	if view, err := group.Get(ctx, "key4"); err != nil || view.ToString() != "local:key4" {
+		t.Fatalf("open breaker should fall back to local, got %s err %v", view.ToString(), err)
+	}
+	if peer.requests.Load() != 4 || group.Stats().PeerRejected != 1 {
+		t.Fatalf("open breaker should reject requests, got %d requests %d rejected", peer.requests.Load(), group.Stats().PeerRejected)
+	}
+
+	peer.down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if state, _ := group.PeerBreakerState("key"); state != misakacache.BreakerHalfOpen {
+		t.Fatalf("breaker should be half-open after open timeout, got %v", state)
+	}
+	if view, err := group.Get(ctx, "key5"); err != nil || view.ToString() != "peer:key5" {
+		t.Fatalf("half-open breaker should let a probe through, got %s err %v", view.ToString(), err)
+	}
+	if state, _ := group.PeerBreakerState("key"); state != misakacache.BreakerClosed {
+		t.Fatalf("breaker should be closed after a successful probe, got %v", state)
+	}
+}
+
+func TestGroup_CircuitBreakerIgnoresNotFound(t *testing.T) {
+	group := newBreakerGroup("breaker-not-found", ringPicker{{name: "peer"}}, misakacache.WithCircuitBreaker(misakacache.BreakerConfig{MinRequests: 1}))
+	if _, err := group.Get(context.Background(), "unknown"); !errors.Is(err, misakacache.ErrNotFound) {
+		t.Fatalf("not found should be propagated from peer, got %v", err)
+	}
+	if state, _ := group.PeerBreakerState("unknown"); state != misakacache.BreakerClosed {
+		t.Fatalf("not found should not open the breaker, got %v", state)
+	}
+}
+
+func TestGroup_PeerFallback(t *testing.T) {
+	ctx := context.Background()
+	breaker := misakacache.WithCircuitBreaker(misakacache.BreakerConfig{MinRequests: 1, OpenTimeout: time.Minute})
+
+	owner, next := &flakyPeer{name: "owner"}, &flakyPeer{name: "next"}
+	owner.down.Store(true)
+	group := newBreakerGroup("fallback-next", ringPicker{owner, next}, breaker, misakacache.WithPeerFallback(misakacache.FallbackNextPeer))
+	for _, key := range []string{"Tom", "Jack"} {
+		if view, err := group.Get(ctx, key); err != nil || view.ToString() != "next:"+key {
+			t.Fatalf("failed owner should fall back to next peer, got %s err %v", view.ToString(), err)
+		}
+	}
+	if owner.requests.Load() != 1 || next.requests.Load() != 2 {
+		t.Fatalf("open breaker should skip owner, got owner %d next %d", owner.requests.Load(), next.requests.Load())
+	}
+	views, err := group.GetMany(ctx, []string{"Sam", "Bob"})
+	if err != nil || views["Sam"].ToString() != "next:Sam" || views["Bob"].ToString() != "next:Bob" {
+		t.Fatalf("batch get should fall back to next peer, got %v err %v", views, err)
+	}
+
+	peer := &flakyPeer{name: "peer"}
+	peer.down.Store(true)
+	group = newBreakerGroup("fallback-error", ringPicker{peer}, breaker, misakacache.WithPeerFallback(misakacache.FallbackError))
+	if _, err := group.Get(ctx, "Tom"); !errors.Is(err, misakacache.ErrUnavailable) {
+		t.Fatalf("failed peer should return error, got %v", err)
+	}
+	if _, err := group.Get(ctx, "Tom"); !errors.Is(err, misakacache.ErrUnavailable) || peer.requests.Load() != 1 {
+		t.Fatalf("open breaker should return error without request, got %v after %d requests", err, peer.requests.Load())
+	}
+}
+
+func TestGroup_CircuitBreakerPrune(t *testing.T) {
+	peer := &flakyPeer{name: "peer"}
+	peer.down.Store(true)
+	picker := &listPicker{}
+	picker.set(peer)
+	group := newBreakerGroup("breaker-prune", picker, misakacache.WithCircuitBreaker(misakacache.BreakerConfig{MinRequests: 1, OpenTimeout: time.Minute}))
+	ctx := context.Background()
+
+	group.Get(ctx, "Tom")
+	if state, _ := group.PeerBreakerState("Tom"); state != misakacache.BreakerOpen {
+		t.Fatalf("breaker should be open after a failure, got %v", state)
+	}
+
+	// 节点被移除后它的熔断器也被删除 重新加入时是一个新的熔断器
+	picker.set()
+	if view, err := group.Get(ctx, "Jack"); err != nil || view.ToString() != "local:Jack" {
+		t.Fatalf("key should be loaded locally without peers, got %s err %v", view.ToString(), err)
+	}
+	picker.set(peer)
+	if state, _ := group.PeerBreakerState("Tom"); state != misakacache.BreakerClosed {
+		t.Fatalf("breaker of a removed peer should be dropped, got %v", state)
+	}
+}
+
+func TestGroup_PeerSuccessSkipsLocal(t *testing.T) {
+	var loads atomic.Int32
+	group, _ := misakacache.NewRegistry().NewGroup("peer-success", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		loads.Add(1)
+		return []byte("local:" + key), nil
+	}))
+	peer := &flakyPeer{name: "peer"}
+	group.RegisterPeers(ringPicker{peer})
+	ctx := context.Background()
+
+	// 远程节点返回成功时直接使用它的值 不应该再从本地加载
+	if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "peer:Tom" || loads.Load() != 0 {
+		t.Fatalf("peer success should not load locally, got %s err %v after %d loads", view.ToString(), err, loads.Load())
+	}
+	peer.down.Store(true)
+	if view, err := group.Get(ctx, "Jack"); err != nil || view.ToString() != "local:Jack" || loads.Load() != 1 {
+		t.Fatalf("peer failure should load locally once, got %s err %v after %d loads", view.ToString(), err, loads.Load())
+	}
+}
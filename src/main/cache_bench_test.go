@@ -0,0 +1,58 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"strconv"
+	"testing"
+)
+
+// benchmarkGroupGet 预先加载keyNumber个键 然后并发地读取 所有读取都会命中
+func benchmarkGroupGet(b *testing.B, name string, opts ...misakacache.GroupOption) {
+	const keyNumber = 1024
+	group, _ := misakacache.NewRegistry().NewGroup(name, misakacache.GetterFunc( // 基准测试函数会被调用多次 每次使用新的Registry
+		func(key string) ([]byte, error) {
+			return []byte("value" + key), nil
+		}), append([]misakacache.GroupOption{misakacache.WithCacheBytes(1 << 20)}, opts...)...)
+	keys := make([]string, keyNumber)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		group.GetFromCache(keys[i])
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := group.GetFromCache(keys[i%keyNumber]); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkGroupGet_Locked 和最初的cache一样 只有一个分片 每次读取都拿写锁
+func BenchmarkGroupGet_Locked(b *testing.B) {
+	benchmarkGroupGet(b, "bench-locked", misakacache.WithBufferedReads(false))
+}
+
+// BenchmarkGroupGet_Sharded 多个分片 每次读取都拿分片的写锁
+func BenchmarkGroupGet_Sharded(b *testing.B) {
+	benchmarkGroupGet(b, "bench-sharded", misakacache.WithBufferedReads(false), misakacache.WithShards(16))
+}
+
+// BenchmarkGroupGet_Buffered 只有一个分片 读取只拿读锁
+func BenchmarkGroupGet_Buffered(b *testing.B) {
+	benchmarkGroupGet(b, "bench-buffered")
+}
+
+// BenchmarkGroupGet_ShardedBuffered 多个分片 读取只拿读锁
+func BenchmarkGroupGet_ShardedBuffered(b *testing.B) {
+	benchmarkGroupGet(b, "bench-sharded-buffered", misakacache.WithShards(16))
+}
+
+// BenchmarkGroupGet_BufferedLFU 读取只拿读锁 淘汰策略为LFU
+func BenchmarkGroupGet_BufferedLFU(b *testing.B) {
+	benchmarkGroupGet(b, "bench-buffered-lfu", misakacache.WithEvictionPolicy(misakacache.LFUPolicy))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroup_Config(t *testing.T) {
+	var logs strings.Builder
+	logger := log.New(&logs, "", 0)
+	registry := misakacache.NewRegistry()
+	group, _ := registry.NewGroup("config", misakacache.ContextGetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			<-ctx.Done() // 数据源卡住 只能等待加载超时
+			return nil, ctx.Err()
+		}),
+		misakacache.WithCacheBytes(4<<10),
+		misakacache.WithShards(4),
+		misakacache.WithTTL(time.Minute),
+		misakacache.WithLoaderTimeout(20*time.Millisecond),
+		misakacache.WithLogger(logger),
+	)
+
+	config := group.Config()
+	if config.Name != "config" || config.CacheBytes != 4<<10 || config.Shards != 4 ||
+		config.DefaultTTL != time.Minute || config.LoaderTimeout != 20*time.Millisecond || !config.BufferedReads || config.Logger != logger {
+		t.Fatalf("unexpected config %+v", config)
+	}
+
+	// 远程节点不可用 失败信息写入指定的logger 之后从本地加载
+	peer := &flakyPeer{name: "peer"}
+	peer.down.Store(true)
+	group.RegisterPeers(ringPicker{peer})
+	start := time.Now()
+	if _, err := group.Get(context.Background(), "Tom"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("load should time out, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("load should stop after loader timeout")
+	}
+	if !strings.Contains(logs.String(), "Failed to get from peer: peer is down") {
+		t.Fatalf("peer failure should be written to the configured logger, got %q", logs.String())
+	}
+
+	if defaults, _ := registry.NewGroup("defaults", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return nil, nil
+	})); defaults.Config().CacheBytes != 64<<20 || defaults.Config().Shards != 1 {
+		t.Fatalf("unexpected default config %+v", defaults.Config())
+	}
+}
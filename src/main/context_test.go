@@ -0,0 +1,84 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_GetContext(t *testing.T) {
+	release := make(chan struct{})
+	group, _ := misakacache.NewRegistry().NewGroup("context", misakacache.ContextGetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			select {
+			case <-release:
+				return []byte("value"), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}), misakacache.WithCacheBytes(2<<10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := group.Get(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancelled caller should get DeadlineExceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("cancelled caller should stop waiting")
+	}
+
+	// 等待中的调用方不受第一个调用方取消的影响
+	first, cancelFirst := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		group.Get(first, "shared")
+	}()
+	var view misakacache.ByteView
+	var err error
+	go func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond)
+		view, err = group.Get(context.Background(), "shared")
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelFirst()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if err != nil || view.ToString() != "value" {
+		t.Fatalf("waiting caller should retry after the first caller was cancelled, err %v", err)
+	}
+}
+
+func TestGroup_LoaderTimeoutShared(t *testing.T) {
+	var loads atomic.Int32
+	group, _ := misakacache.NewRegistry().NewGroup("loader-timeout", misakacache.ContextGetterFunc(
+		func(ctx context.Context, key string) ([]byte, error) {
+			loads.Add(1)
+			<-ctx.Done() // 数据源卡住 只能等待加载超时
+			return nil, ctx.Err()
+		}), misakacache.WithLoaderTimeout(50*time.Millisecond))
+
+	// 加载自己超时时 等待中的调用方共享这个结果 不再依次重新发起加载
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := group.Get(context.Background(), "key"); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("load should time out, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if loads.Load() != 1 {
+		t.Fatalf("getter should run once for concurrent callers, got %d", loads.Load())
+	}
+}
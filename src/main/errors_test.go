@@ -0,0 +1,136 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHTTPPool_ErrorResponse(t *testing.T) {
+	registry := misakacache.NewRegistry()
+	registry.NewGroup("errors", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		if key == "down" {
+			return nil, fmt.Errorf("database is down: %w", misakacache.ErrUnavailable)
+		}
+		return nil, fmt.Errorf("%s not exist: %w", key, misakacache.ErrNotFound)
+	}))
+	server := httptest.NewServer(registry.NewHTTPPool("server").Handler())
+	defer server.Close()
+
+	for key, want := range map[string]struct {
+		code   int
+		status pb.Status
+	}{
+		"down":    {http.StatusServiceUnavailable, pb.Status_STATUS_UNAVAILABLE},
+		"unknown": {http.StatusNotFound, pb.Status_STATUS_NOT_FOUND},
+	} {
+		resp, err := http.Get(server.URL + "/_geecache/errors/" + key)
+		if err != nil {
+			t.Fatalf("failed to request %s, err %v", key, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		e := &pb.Error{}
+		if err = proto.Unmarshal(body, e); err != nil {
+			t.Fatalf("error body of %s should be an Error message, err %v", key, err)
+		}
+		if resp.StatusCode != want.code || e.GetStatus() != want.status {
+			t.Fatalf("%s should get %d %v, got %d %v", key, want.code, want.status, resp.StatusCode, e.GetStatus())
+		}
+	}
+}
+
+func TestHTTPPool_PeerError(t *testing.T) {
+	// 远程节点没有这个Group 返回400 请求方不应该再从本地加载
+	server := newPeerServer("other", func(handler http.Handler) http.Handler { return handler })
+	defer server.Close()
+
+	group := newClientGroup("bad-request", server.URL)
+	_, err := group.Get(context.Background(), "Tom")
+	var peerErr *misakacache.PeerError
+	if !errors.As(err, &peerErr) || peerErr.Status != pb.Status_STATUS_BAD_REQUEST || !errors.Is(err, misakacache.ErrBadRequest) {
+		t.Fatalf("bad request should be propagated from peer, got %v", err)
+	}
+	if _, err = group.GetMany(context.Background(), []string{"Tom", "Sam"}); !errors.Is(err, misakacache.ErrBadRequest) {
+		t.Fatalf("bad request should be propagated from peer on batch get, got %v", err)
+	}
+}
+
+func TestHTTPPool_ProxyError(t *testing.T) {
+	// 代理返回的错误响应体不是Error 根据状态码推断为节点不可用 从本地加载
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	group := newClientGroup("proxy", server.URL)
+	if view, err := group.Get(context.Background(), "Tom"); err != nil || view.ToString() != "client:Tom" {
+		t.Fatalf("unavailable peer should fall back to local, got %s err %v", view.ToString(), err)
+	}
+}
+
+func TestGRPCPool_PeerError(t *testing.T) {
+	serverRegistry := misakacache.NewRegistry()
+	serverRegistry.NewGroup("grpc-other", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return nil, fmt.Errorf("database is down: %w", misakacache.ErrUnavailable)
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen, err %v", err)
+	}
+	server := serverRegistry.NewGRPCPool(listener.Addr().String()).NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	clientRegistry := misakacache.NewRegistry()
+	newGroup := func(name string) *misakacache.Group {
+		group, _ := clientRegistry.NewGroup(name, misakacache.GetterFunc(func(key string) ([]byte, error) {
+			return []byte("client:" + key), nil
+		}))
+		return group
+	}
+	pool := clientRegistry.NewGRPCPool("client")
+	if err = pool.SetNewPeer(listener.Addr().String()); err != nil {
+		t.Fatalf("failed to set peers, err %v", err)
+	}
+	defer pool.Close()
+	ctx := context.Background()
+
+	badGroup := newGroup("grpc-errors")
+	badGroup.RegisterPeers(pool)
+	if _, err := badGroup.Get(ctx, "Tom"); !errors.Is(err, misakacache.ErrBadRequest) {
+		t.Fatalf("bad request should be propagated from peer, got %v", err)
+	}
+
+	downGroup := newGroup("grpc-other")
+	downGroup.RegisterPeers(pool)
+	if view, err := downGroup.Get(ctx, "Tom"); err != nil || view.ToString() != "client:Tom" {
+		t.Fatalf("unavailable peer should fall back to local, got %s err %v", view.ToString(), err)
+	}
+}
+
+func TestPeerError_Unwrap(t *testing.T) {
+	for status, want := range map[pb.Status]error{
+		pb.Status_STATUS_NOT_FOUND:         misakacache.ErrNotFound,
+		pb.Status_STATUS_BAD_REQUEST:       misakacache.ErrBadRequest,
+		pb.Status_STATUS_UNAVAILABLE:       misakacache.ErrUnavailable,
+		pb.Status_STATUS_DEADLINE_EXCEEDED: context.DeadlineExceeded,
+	} {
+		err := fmt.Errorf("get from peer: %w", &misakacache.PeerError{Status: status})
+		if !errors.Is(err, want) {
+			t.Fatalf("%v should unwrap to %v", status, want)
+		}
+	}
+	if err := (&misakacache.PeerError{Status: pb.Status_STATUS_INTERNAL}); errors.Unwrap(err) != nil {
+		t.Fatalf("internal error should not unwrap, got %v", errors.Unwrap(err))
+	}
+}
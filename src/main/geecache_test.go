@@ -16,7 +16,7 @@ package main
 //
 //func TestGet(t *testing.T) {
 //	loadCounts := make(map[string]int, len(db))
-//	gee := misakacache.NewGroup("scores", 2<<10, misakacache.GetterFunc(
+//	gee := misakacache.NewGroup("scores", misakacache.GetterFunc(
 //		func(key string) ([]byte, error) {
 //			log.Println("[SlowDB] search key", key)
 //			if v, ok := db[key]; ok {
@@ -27,7 +27,7 @@ package main
 //				return []byte(v), nil
 //			}
 //			return nil, fmt.Errorf("%s not exist", key)
-//		}))
+//		}), misakacache.WithCacheBytes(2<<10))
 //
 //	for k, v := range db {
 //		if view, err := gee.GetFromCache(k); err != nil || view.ToString() != v {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestGRPCPool(t *testing.T) {
+	getter := misakacache.GetterFunc(func(key string) ([]byte, error) {
+		if key == "unknown" {
+			return nil, fmt.Errorf("%s not exist: %w", key, misakacache.ErrNotFound)
+		}
+		return []byte("server:" + key), nil
+	})
+
+	// 服务端和客户端使用不同的Registry 模拟两个进程
+	serverRegistry := misakacache.NewRegistry()
+	serverGroup, _ := serverRegistry.NewGroup("grpc", getter)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen, err %v", err)
+	}
+	server := serverRegistry.NewGRPCPool(listener.Addr().String()).NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	clientRegistry := misakacache.NewRegistry()
+	clientGroup, _ := clientRegistry.NewGroup("grpc", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte("client:" + key), nil
+	}))
+	pool := clientRegistry.NewGRPCPool("client")
+	if err = pool.SetNewPeer(listener.Addr().String()); err != nil {
+		t.Fatalf("failed to set peers, err %v", err)
+	}
+	defer pool.Close()
+	clientGroup.RegisterPeers(pool)
+	ctx := context.Background()
+
+	if view, err := clientGroup.Get(ctx, "Tom"); err != nil || view.ToString() != "server:Tom" {
+		t.Fatalf("failed to get Tom from peer, got %s err %v", view.ToString(), err)
+	}
+	if _, err := clientGroup.Get(ctx, "unknown"); !errors.Is(err, misakacache.ErrNotFound) {
+		t.Fatalf("not found should be propagated from peer, got %v", err)
+	}
+
+	if err := clientGroup.Set(ctx, "Jack", []byte("589"), 0); err != nil {
+		t.Fatalf("failed to set Jack on peer, err %v", err)
+	}
+	if view, _ := serverGroup.Get(ctx, "Jack"); view.ToString() != "589" {
+		t.Fatalf("value should be set on peer, got %s", view.ToString())
+	}
+	if err := clientGroup.Remove(ctx, "Jack"); err != nil {
+		t.Fatalf("failed to remove Jack on peer, err %v", err)
+	}
+	if view, _ := serverGroup.Get(ctx, "Jack"); view.ToString() != "server:Jack" {
+		t.Fatalf("value should be removed on peer, got %s", view.ToString())
+	}
+
+	views, err := clientGroup.GetMany(ctx, []string{"Tom", "Sam", "unknown"})
+	if err != nil || len(views) != 2 || views["Sam"].ToString() != "server:Sam" {
+		t.Fatalf("failed to get many from peer, got %v err %v", views, err)
+	}
+}
+
+func TestGRPCPool_SetNewPeerError(t *testing.T) {
+	pool := misakacache.NewGRPCPool("self")
+	defer pool.Close()
+	if err := pool.SetNewPeer("127.0.0.1:1", "%zz"); err == nil {
+		t.Fatalf("invalid target should fail")
+	}
+	// 失败时保持原来的远程节点不变 不能返回包着nil的接口
+	if peer, ok := pool.PickPeer("Tom"); ok || peer != nil {
+		t.Fatalf("no peer should be picked after a failed update, got %v", peer)
+	}
+	if err := pool.SetNewPeer("127.0.0.1:1", "self"); err != nil {
+		t.Fatalf("failed to set peers, err %v", err)
+	}
+	for _, key := range []string{"Tom", "Jack", "Sam"} {
+		if peer, ok := pool.PickPeer(key); ok && peer == nil {
+			t.Fatalf("picked peer should not be nil")
+		}
+	}
+	// 重复的地址只建立一条连接
+	if err := pool.SetNewPeer("127.0.0.1:2", "127.0.0.1:2"); err != nil || len(pool.Peers()) != 1 {
+		t.Fatalf("duplicate peers should share one client, got %d err %v", len(pool.Peers()), err)
+	}
+}
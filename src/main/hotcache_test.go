@@ -0,0 +1,108 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"testing"
+	"time"
+)
+
+// countingPeer 测试用的远程节点 记录收到的请求次数 所有key都属于它
+type countingPeer struct {
+	requests int
+}
+
+func (p *countingPeer) PickPeer(key string) (misakacache.PeerCacheValueGetter, bool) {
+	return p, true
+}
+
+func (p *countingPeer) GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	p.requests++
+	out.Value = []byte("peer:" + in.GetKey())
+	return nil
+}
+
+func (p *countingPeer) SetCacheToPeer(ctx context.Context, in *pb.SetRequest) error {
+	return nil
+}
+
+func (p *countingPeer) RemoveCacheFromPeer(ctx context.Context, in *pb.RemoveRequest) error {
+	return nil
+}
+
+func TestGroup_HotCache(t *testing.T) {
+	peer := &countingPeer{}
+	group, _ := misakacache.NewRegistry().NewGroup("hot", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("local"), nil
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithHotCache(0.25, 1, 0), misakacache.WithTTL(20*time.Millisecond))
+	group.RegisterPeers(peer)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "peer:Tom" {
+			t.Fatalf("failed to get Tom from peer, got %s err %v", view.ToString(), err)
+		}
+	}
+	if peer.requests != 1 {
+		t.Fatalf("remote value should be mirrored in hot cache, peer got %d requests", peer.requests)
+	}
+
+	group.Remove(ctx, "Tom") // 修改远程节点上的值时 本地的副本也被删除
+	group.Get(ctx, "Tom")
+	if peer.requests != 2 {
+		t.Fatalf("hot copy should be dropped after Remove, peer got %d requests", peer.requests)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	group.Get(ctx, "Tom")
+	if peer.requests != 3 {
+		t.Fatalf("hot copy should expire with the default ttl, peer got %d requests", peer.requests)
+	}
+
+	if config := group.Config(); config.HotCacheRatio != 0.25 || config.HotCacheSampleRate != 1 {
+		t.Fatalf("unexpected config %+v", config)
+	}
+}
+
+func TestGroup_HotCacheTTL(t *testing.T) {
+	peer := &countingPeer{}
+	group, _ := misakacache.NewRegistry().NewGroup("hot-ttl", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("local"), nil
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithHotCache(0.25, 1, 20*time.Millisecond))
+	group.RegisterPeers(peer)
+	ctx := context.Background()
+
+	// 没有设置默认存活时间 热点缓存中的副本也要在自己的存活时间之后过期
+	group.Get(ctx, "Tom")
+	group.Get(ctx, "Tom")
+	time.Sleep(30 * time.Millisecond)
+	group.Get(ctx, "Tom")
+	if peer.requests != 2 {
+		t.Fatalf("hot copy should expire after hot cache ttl, peer got %d requests", peer.requests)
+	}
+	if config := group.Config(); config.HotCacheTTL != 20*time.Millisecond {
+		t.Fatalf("unexpected hot cache ttl %v", config.HotCacheTTL)
+	}
+
+	group, _ = misakacache.NewRegistry().NewGroup("hot-default-ttl", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("local"), nil
+		}), misakacache.WithHotCache(0.25, 1, 0))
+	if group.Config().HotCacheTTL <= 0 {
+		t.Fatalf("hot cache ttl should have a non-zero default")
+	}
+}
+
+func TestGroup_HotCacheRatio(t *testing.T) {
+	getter := misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte("local"), nil
+	})
+	for _, ratio := range []float64{0, -0.5, 1, 1.5} {
+		if _, err := misakacache.NewRegistry().NewGroup("hot-ratio", getter, misakacache.WithHotCache(ratio, 1, 0)); err == nil {
+			t.Fatalf("hot cache ratio %v should be rejected", ratio)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// newPeerServer 在单独的Registry中创建远程节点 handler可以对请求做额外的处理
+func newPeerServer(name string, wrap func(http.Handler) http.Handler, opts ...misakacache.HTTPPoolOption) *httptest.Server {
+	registry := misakacache.NewRegistry()
+	registry.NewGroup(name, misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte("server:" + key), nil
+	}))
+	return httptest.NewServer(wrap(registry.NewHTTPPool("server", opts...).Handler()))
+}
+
+// newClientGroup 在单独的Registry中创建Group 所有key都属于server
+func newClientGroup(name, server string, opts ...misakacache.HTTPPoolOption) *misakacache.Group {
+	registry := misakacache.NewRegistry()
+	group, _ := registry.NewGroup(name, misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte("client:" + key), nil
+	}))
+	pool := registry.NewHTTPPool("client", opts...)
+	pool.SetNewPeer(server)
+	group.RegisterPeers(pool)
+	return group
+}
+
+func TestHTTPPool_HTTP2Cleartext(t *testing.T) {
+	var protoMajor atomic.Int32
+	server := newPeerServer("h2c", func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			protoMajor.Store(int32(r.ProtoMajor))
+			handler.ServeHTTP(w, r)
+		})
+	}, misakacache.WithHTTP2Cleartext())
+	defer server.Close()
+
+	group := newClientGroup("h2c", server.URL, misakacache.WithHTTP2Cleartext())
+	if view, err := group.Get(context.Background(), "Tom"); err != nil || view.ToString() != "server:Tom" {
+		t.Fatalf("failed to get Tom from peer, got %s err %v", view.ToString(), err)
+	}
+	if protoMajor.Load() != 2 {
+		t.Fatalf("peer request should use HTTP/2, got HTTP/%d", protoMajor.Load())
+	}
+}
+
+func TestHTTPPool_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := newPeerServer("timeout", func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release // 远程节点卡住
+		})
+	})
+	defer server.Close()
+	defer close(release)
+
+	group := newClientGroup("timeout", server.URL, misakacache.WithRequestTimeout(20*time.Millisecond))
+	start := time.Now()
+	if view, err := group.Get(context.Background(), "Tom"); err != nil || view.ToString() != "client:Tom" {
+		t.Fatalf("stalled peer should fall back to local, got %s err %v", view.ToString(), err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("request to stalled peer should time out")
+	}
+}
+
+// countingTransport 记录请求次数的RoundTripper
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (transport *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	transport.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPPool_Transport(t *testing.T) {
+	server := newPeerServer("transport", func(handler http.Handler) http.Handler { return handler })
+	defer server.Close()
+
+	transport := &countingTransport{}
+	group := newClientGroup("transport", server.URL, misakacache.WithTransport(transport), misakacache.WithMaxIdleConnsPerPeer(4))
+	group.Get(context.Background(), "Tom")
+	if transport.requests.Load() != 1 {
+		t.Fatalf("peer requests should go through the custom transport, got %d", transport.requests.Load())
+	}
+
+	registry := misakacache.NewRegistry()
+	if custom := registry.NewHTTPPool("custom", misakacache.WithTransport(transport), misakacache.WithMaxIdleConnsPerPeer(4)); custom.Transport() != transport {
+		t.Fatalf("custom transport should be used as is, got %T", custom.Transport())
+	}
+	built, ok := registry.NewHTTPPool("limited", misakacache.WithMaxIdleConnsPerPeer(4)).Transport().(*http.Transport)
+	if !ok || built.MaxIdleConnsPerHost != 4 {
+		t.Fatalf("built transport should keep 4 idle connections per peer, got %+v", built)
+	}
+	if _, ok := registry.NewHTTPPool("h2c", misakacache.WithHTTP2Cleartext(), misakacache.WithMaxIdleConnsPerPeer(4)).Transport().(*http2.Transport); !ok {
+		t.Fatalf("h2c pool should use an HTTP/2 transport")
+	}
+}
+
+func TestHTTPPool_BasePathAndHash(t *testing.T) {
+	server := newPeerServer("base-path", func(handler http.Handler) http.Handler { return handler },
+		misakacache.WithBasePath("cluster-a"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/cluster-a/base-path/Tom")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("pool should serve the configured base path, err %v", err)
+	}
+	resp.Body.Close()
+
+	var hashes atomic.Int32
+	group := newClientGroup("base-path", server.URL,
+		misakacache.WithBasePath("/cluster-a/"),
+		misakacache.WithReplicas(3),
+		misakacache.WithHashFunc(func(data []byte) uint32 {
+			hashes.Add(1)
+			return crc32.ChecksumIEEE(data)
+		}))
+	if hashes.Load() != 3 {
+		t.Fatalf("each real node should have 3 replicas hashed by the custom function, got %d", hashes.Load())
+	}
+	if view, err := group.Get(context.Background(), "Tom"); err != nil || view.ToString() != "server:Tom" {
+		t.Fatalf("failed to get Tom from peer, got %s err %v", view.ToString(), err)
+	}
+
+	// 虚拟节点数量不合法时使用默认值 否则选不出远程节点
+	group = newClientGroup("base-path", server.URL, misakacache.WithBasePath("cluster-a"), misakacache.WithReplicas(0))
+	if view, err := group.Get(context.Background(), "Tom"); err != nil || view.ToString() != "server:Tom" {
+		t.Fatalf("zero replicas should fall back to the default, got %s err %v", view.ToString(), err)
+	}
+}
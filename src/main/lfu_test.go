@@ -0,0 +1,76 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache/lfu"
+	"MisakaCache/src/misakacache/lru"
+	"reflect"
+	"testing"
+)
+
+func TestLFU_GetValue(t *testing.T) {
+	cache := lfu.NewLFU(int64(100), nil)
+	cache.SetValue("key1", String("1234"))
+	if value, isOk := cache.GetValue("key1"); !isOk || value != String("1234") {
+		t.Fatalf("cache hit key1=1234 failed, value actually is %s", value)
+	}
+	if _, isOk := cache.GetValue("key2"); isOk {
+		t.Fatalf("cache miss key2 failed")
+	}
+	if frequency := cache.GetFrequency("key1"); frequency != 2 {
+		t.Fatalf("frequency of key1 should be 2, actually is %d", frequency)
+	}
+}
+
+func TestLFU_RemoveOldestCache(t *testing.T) {
+	key1, key2, key3 := "key1", "key2", "key3"
+	value1, value2, value3 := "value1", "value2", "value3"
+
+	capacity := len(key1 + key2 + value1 + value2)
+	cache := lfu.NewLFU(int64(capacity), nil)
+	cache.SetValue(key1, String(value1))
+	cache.SetValue(key2, String(value2))
+	cache.GetValue(key1) // key1被访问过两次 应当淘汰key2
+	cache.SetValue(key3, String(value3))
+
+	if _, isOk := cache.GetValue(key2); isOk || cache.GetLFUEntryNumber() != 2 {
+		t.Fatalf("Removeoldest key2 failed")
+	}
+	if _, isOk := cache.GetValue(key1); !isOk {
+		t.Fatalf("frequently used key1 should not be removed")
+	}
+}
+
+func TestLFU_OnEntryDeleted(t *testing.T) {
+	keys := make([]string, 0)
+	callback := func(key string, value lru.Value) {
+		keys = append(keys, key)
+	}
+	cache := lfu.NewLFU(int64(20), callback)
+	cache.SetValue("key1", String("value1"))
+	cache.SetValue("key2", String("value2"))
+	cache.SetValue("key3", String("value3"))
+	cache.SetValue("key4", String("value3"))
+	expected := []string{"key1", "key2"}
+
+	if !reflect.DeepEqual(expected, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, now keys are %s", expected, keys)
+	}
+}
+
+func TestLFU_Aging(t *testing.T) {
+	cache := lfu.NewLFU(int64(100), nil)
+	cache.SetValue("old", String("1"))
+	cache.SetValue("new", String("1"))
+	for i := 0; i < 30; i++ {
+		cache.GetValue("old")
+	}
+	if frequency := cache.GetFrequency("old"); frequency >= 31 {
+		t.Fatalf("frequency of old should be aged, actually is %d", frequency)
+	}
+	if cache.GetFrequency("new") != 1 {
+		t.Fatalf("frequency should never be aged below 1")
+	}
+	if cache.BytesUsed() != int64(len("old1new1")) || cache.Len() != 2 {
+		t.Fatalf("memory accounting broken after aging, used %d", cache.BytesUsed())
+	}
+}
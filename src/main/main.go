@@ -2,6 +2,7 @@ package main
 
 import (
 	"MisakaCache/src/misakacache"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -15,15 +16,15 @@ var db = map[string]string{
 }
 
 func createGroup() *misakacache.Group {
-	return misakacache.NewGroup("scores", 2<<10, misakacache.GetterFunc(
+	return misakacache.NewGroup("scores", misakacache.GetterFunc(
 		func(key string) ([]byte, error) {
 			log.Println("[SlowDB] search key", key)
 			if v, ok := db[key]; ok {
 				log.Printf("[SlowDB] searched key: %s", v)
 				return []byte(v), nil
 			}
-			return nil, fmt.Errorf("%s not exist", key)
-		}))
+			return nil, fmt.Errorf("%s not exist: %w", key, misakacache.ErrNotFound)
+		}), misakacache.WithCacheBytes(2<<10))
 }
 
 func startCacheServer(addr string, addrs []string, gee *misakacache.Group) {
@@ -31,14 +32,18 @@ func startCacheServer(addr string, addrs []string, gee *misakacache.Group) {
 	peers.SetNewPeer(addrs...)
 	gee.RegisterPeers(peers)
 	log.Println("misakacache is running at", addr)
-	log.Fatal(http.ListenAndServe(addr[7:], peers))
+	log.Fatal(http.ListenAndServe(addr[7:], peers.Handler()))
 }
 
 func startAPIServer(apiAddr string, gee *misakacache.Group) {
 	http.Handle("/api", http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
 			key := r.URL.Query().Get("key")
-			view, err := gee.GetFromCache(key)
+			view, err := gee.Get(r.Context(), key)
+			if errors.Is(err, misakacache.ErrNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
@@ -46,6 +51,7 @@ func startAPIServer(apiAddr string, gee *misakacache.Group) {
 			w.Header().Set("Content-Type", "application/octet-stream")
 			w.Write(view.GetByteCopy())
 		}))
+	http.Handle("/metrics", misakacache.MetricsHandler())
 	log.Println("fontend server is running at", apiAddr)
 	log.Fatal(http.ListenAndServe(apiAddr[7:], nil))
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_MetricsHandler(t *testing.T) {
+	registry := misakacache.NewRegistry()
+	group, _ := registry.NewGroup("metrics", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("value"), nil
+		}), misakacache.WithHotCache(0.5, 1, 0))
+	group.Get(context.Background(), "Tom")
+	group.Get(context.Background(), "Tom")
+
+	remote, _ := registry.NewGroup(`remote"group`, misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("value"), nil
+		}))
+	remote.RegisterPeers(&countingPeer{})
+	remote.Get(context.Background(), "Jack")
+
+	recorder := httptest.NewRecorder()
+	registry.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %s", recorder.Header().Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	output := string(body)
+	for _, line := range []string{
+		"# TYPE misakacache_group_gets_total counter",
+		`misakacache_group_gets_total{group="metrics"} 2`,
+		`misakacache_group_cache_hits_total{group="metrics"} 1`,
+		`misakacache_cache_hits_total{group="metrics",cache="main"} 1`,
+		`misakacache_group_local_loads_total{group="metrics"} 1`,
+		`misakacache_cache_items{group="metrics",cache="main"} 1`,
+		`misakacache_cache_items{group="metrics",cache="hot"} 0`,
+		`misakacache_group_peer_loads_total{group="remote\"group"} 1`,
+		`misakacache_peer_request_duration_seconds_bucket{group="remote\"group",le="+Inf"} 1`,
+		`misakacache_peer_request_duration_seconds_count{group="remote\"group"} 1`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Fatalf("metrics should contain %q, got\n%s", line, output)
+		}
+	}
+	types := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "# TYPE ") {
+			continue
+		}
+		name := strings.Fields(line)[2]
+		if types[name] { // 同名的指标出现两次时Prometheus会拒绝整个抓取
+			t.Fatalf("metric %s declared more than once", name)
+		}
+		types[name] = true
+	}
+	if strings.Contains(output, `group="remote\"group",cache="hot"`) {
+		t.Fatalf("hot cache metrics should be omitted when hot cache is disabled")
+	}
+}
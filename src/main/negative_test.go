@@ -0,0 +1,40 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGroup_NegativeCache(t *testing.T) {
+	loadCounts := make(map[string]int)
+	group, _ := misakacache.NewRegistry().NewGroup("negative", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			loadCounts[key]++
+			if key == "Tom" {
+				return []byte("630"), nil
+			}
+			return nil, fmt.Errorf("%s not exist: %w", key, misakacache.ErrNotFound)
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithNegativeTTL(20*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		if _, err := group.GetFromCache("unknown"); !errors.Is(err, misakacache.ErrNotFound) {
+			t.Fatalf("unknown should return ErrNotFound, got %v", err)
+		}
+	}
+	if loadCounts["unknown"] != 1 {
+		t.Fatalf("not found result should be cached, loaded %d times", loadCounts["unknown"])
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	group.GetFromCache("unknown")
+	if loadCounts["unknown"] != 2 {
+		t.Fatalf("negative cache should expire, loaded %d times", loadCounts["unknown"])
+	}
+
+	if view, err := group.GetFromCache("Tom"); err != nil || view.ToString() != "630" {
+		t.Fatalf("failed to get value of Tom, err %v", err)
+	}
+}
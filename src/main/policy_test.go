@@ -0,0 +1,40 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"MisakaCache/src/misakacache/lru"
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// countingPolicy 在LRU的基础上记录写入的次数
+type countingPolicy struct {
+	misakacache.EvictionPolicy
+	sets *atomic.Int32
+}
+
+func (p countingPolicy) SetValue(key string, value lru.Value) {
+	p.sets.Add(1)
+	p.EvictionPolicy.SetValue(key, value)
+}
+
+func TestGroup_EvictionPolicy(t *testing.T) {
+	var created, sets atomic.Int32
+	group, _ := misakacache.NewRegistry().NewGroup("policy", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte(key), nil
+	}), misakacache.WithEvictionPolicy(func(maxMemoryBytes int64) misakacache.EvictionPolicy {
+		created.Add(1)
+		return countingPolicy{EvictionPolicy: misakacache.LRUPolicy(maxMemoryBytes), sets: &sets}
+	}))
+
+	ctx := context.Background()
+	for _, key := range []string{"Tom", "Jack", "Tom"} {
+		if view, err := group.Get(ctx, key); err != nil || view.ToString() != key {
+			t.Fatalf("failed to get %s, got %s err %v", key, view.ToString(), err)
+		}
+	}
+	if created.Load() != 1 || sets.Load() != 2 {
+		t.Fatalf("group cache should use the custom policy, got %d created %d sets", created.Load(), sets.Load())
+	}
+}
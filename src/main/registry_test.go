@@ -0,0 +1,70 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	getter := misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte(key), nil
+	})
+	first := misakacache.NewRegistry()
+	second := misakacache.NewRegistry()
+
+	group, err := first.NewGroup("registry", getter, misakacache.WithCacheBytes(2<<10))
+	if err != nil {
+		t.Fatalf("failed to create group, err %v", err)
+	}
+	if _, err = first.NewGroup("registry", getter, misakacache.WithCacheBytes(2<<10)); !errors.Is(err, misakacache.ErrGroupExists) {
+		t.Fatalf("duplicate group name should return ErrGroupExists, got %v", err)
+	}
+	if _, err = second.NewGroup("registry", getter, misakacache.WithCacheBytes(2<<10)); err != nil {
+		t.Fatalf("registries should be isolated, err %v", err)
+	}
+	if first.GetGroup("registry") != group || misakacache.GetGroup("registry") != nil {
+		t.Fatalf("group should only be visible in its own registry")
+	}
+
+	if !first.RemoveGroup("registry") || first.GetGroup("registry") != nil {
+		t.Fatalf("failed to remove group")
+	}
+	if _, err = first.NewGroup("registry", getter, misakacache.WithCacheBytes(2<<10)); err != nil {
+		t.Fatalf("removed group name should be reusable, err %v", err)
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	group := misakacache.NewGroup("default-registry", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte(key), nil
+	}))
+	t.Cleanup(func() { misakacache.RemoveGroup("default-registry") }) // 默认Registry是全局的 测试结束后必须删除
+	if misakacache.GetGroup("default-registry") != group {
+		t.Fatalf("NewGroup should register the group in the default registry")
+	}
+}
+
+func TestRegistry_HTTPPool(t *testing.T) {
+	registry := misakacache.NewRegistry()
+	registry.NewGroup("registry-http", misakacache.GetterFunc(func(key string) ([]byte, error) {
+		return []byte(key), nil
+	}), misakacache.WithCacheBytes(2<<10))
+
+	server := httptest.NewServer(registry.NewHTTPPool("server"))
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/_geecache/registry-http/Tom")
+	if err != nil || resp.StatusCode != 200 {
+		t.Fatalf("pool should serve groups of its registry, err %v", err)
+	}
+	resp.Body.Close()
+
+	other := httptest.NewServer(misakacache.NewHTTPPool("other"))
+	defer other.Close()
+	resp, err = other.Client().Get(other.URL + "/_geecache/registry-http/Tom")
+	if err != nil || resp.StatusCode != 400 {
+		t.Fatalf("default pool should not see groups of other registries, err %v", err)
+	}
+	resp.Body.Close()
+}
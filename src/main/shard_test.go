@@ -0,0 +1,96 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGroup_Shards(t *testing.T) {
+	var loads int32
+	group, _ := misakacache.NewRegistry().NewGroup("shards", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return []byte("value" + key), nil
+		}), misakacache.WithCacheBytes(1<<20), misakacache.WithShards(8))
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := strconv.Itoa(i)
+				if view, err := group.GetFromCache(key); err != nil || view.ToString() != "value"+key {
+					t.Errorf("get %s failed, err %v", key, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 200; i++ { // 加载完成后全部命中
+		group.GetFromCache(strconv.Itoa(i))
+	}
+	before := atomic.LoadInt32(&loads)
+	for i := 0; i < 200; i++ {
+		group.GetFromCache(strconv.Itoa(i))
+	}
+	if after := atomic.LoadInt32(&loads); after != before {
+		t.Fatalf("cached keys should not be loaded again, loaded %d more times", after-before)
+	}
+}
+
+func TestGroup_BufferedReadsKeepHotSet(t *testing.T) {
+	var hotLoads atomic.Int32
+	group, _ := misakacache.NewRegistry().NewGroup("hot-set", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			if key[0] == 'h' {
+				hotLoads.Add(1)
+			}
+			return []byte("0123456789"), nil
+		}), misakacache.WithCacheBytes(32*16)) // 每条缓存16字节 一共32条
+
+	hot := make([]string, 8)
+	for i := range hot {
+		hot[i] = fmt.Sprintf("hot%03d", i)
+		group.GetFromCache(hot[i])
+	}
+
+	// 并发读取热点key的同时不断写入新的key 命中记录必须能交给淘汰策略 热点key才不会被淘汰
+	var reads atomic.Int64
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				group.GetFromCache(hot[i%len(hot)])
+				reads.Add(1)
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		group.GetFromCache(fmt.Sprintf("c%05d", i))
+		for target := reads.Load() + 1024; reads.Load() < target; {
+			runtime.Gosched()
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if hotLoads.Load() != int32(len(hot)) {
+		t.Fatalf("hot keys should survive eviction under concurrent reads, loaded %d times", hotLoads.Load())
+	}
+}
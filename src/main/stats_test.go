@@ -0,0 +1,78 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroup_Stats(t *testing.T) {
+	var mu sync.Mutex
+	events := make(map[misakacache.StatsEvent]int)
+	release := make(chan struct{})
+	group, _ := misakacache.NewRegistry().NewGroup("stats", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			if key == "slow" {
+				<-release
+			}
+			if key == "unknown" {
+				return nil, fmt.Errorf("%s not exist: %w", key, misakacache.ErrNotFound)
+			}
+			if key == "broken" {
+				return nil, fmt.Errorf("database is down")
+			}
+			return []byte("value"), nil
+		}),
+		misakacache.WithCacheBytes(64),
+		misakacache.WithStatsHook(func(group string, event misakacache.StatsEvent, latency time.Duration) {
+			mu.Lock()
+			events[event]++
+			mu.Unlock()
+		}))
+	ctx := context.Background()
+
+	group.Get(ctx, "Tom")
+	group.Get(ctx, "Tom")
+	group.Get(ctx, "unknown")
+	group.Get(ctx, "broken")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			group.Get(ctx, "slow")
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// 写满内存 触发淘汰
+	for i := 0; i < 10; i++ {
+		group.Get(ctx, fmt.Sprintf("key%d", i))
+	}
+
+	stats := group.Stats()
+	if stats.Gets != 16 || stats.CacheHits != 1 || stats.LocalLoads != 13 || stats.LoadErrors != 1 || stats.LoadsDeduped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if stats.Evictions == 0 || stats.Bytes == 0 || stats.Bytes > 64 || stats.Items == 0 {
+		t.Fatalf("unexpected cache stats %+v", stats)
+	}
+	main := group.CacheStats(misakacache.MainCache)
+	if main.Hits != 1 || main.Evictions != stats.Evictions {
+		t.Fatalf("unexpected main cache stats %+v", main)
+	}
+	if hot := group.CacheStats(misakacache.HotCache); hot != (misakacache.CacheStats{}) {
+		t.Fatalf("hot cache is disabled, got %+v", hot)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if events[misakacache.EventGet] != 16 || events[misakacache.EventLoadDeduped] != 1 {
+		t.Fatalf("stats hook should see every event, got %v", events)
+	}
+}
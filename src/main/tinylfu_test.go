@@ -0,0 +1,93 @@
+package main
+
+import (
+	lru2 "MisakaCache/src/misakacache/lru"
+	"MisakaCache/src/misakacache/tinylfu"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTinyLFU_GetValue(t *testing.T) {
+	cache := tinylfu.NewTinyLFU(int64(100), nil)
+	cache.SetValue("key1", String("1234"))
+	if value, isOk := cache.GetValue("key1"); !isOk || value != String("1234") {
+		t.Fatalf("cache hit key1=1234 failed, value actually is %s", value)
+	}
+	if _, isOk := cache.GetValue("key2"); isOk {
+		t.Fatalf("cache miss key2 failed")
+	}
+	if !cache.RemoveValue("key1") || cache.Len() != 0 || cache.BytesUsed() != 0 {
+		t.Fatalf("remove key1 failed")
+	}
+}
+
+func TestTinyLFU_Admission(t *testing.T) {
+	rejected := make([]string, 0)
+	cache := tinylfu.NewTinyLFUWithCounters(int64(200), 64, func(key string, value lru2.Value) {
+		rejected = append(rejected, key)
+	})
+	// 热点键被访问多次 积累访问次数
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 10; i++ {
+			key := "hot" + strconv.Itoa(i)
+			if _, isOk := cache.GetValue(key); !isOk {
+				cache.SetValue(key, String("value"))
+			}
+		}
+	}
+	// 只访问一次的键不应该把热点键挤出去
+	for i := 0; i < 100; i++ {
+		key := "once" + strconv.Itoa(i)
+		if _, isOk := cache.GetValue(key); !isOk {
+			cache.SetValue(key, String("value"))
+		}
+	}
+	hits := 0
+	for i := 0; i < 10; i++ {
+		if _, isOk := cache.GetValue("hot" + strconv.Itoa(i)); isOk {
+			hits++
+		}
+	}
+	if hits < 9 {
+		t.Fatalf("one-hit-wonder keys pushed out the hot set, only %d hot keys left", hits)
+	}
+	if len(rejected) == 0 || cache.BytesUsed() > 200 {
+		t.Fatalf("admission filter did not reject anything, used %d", cache.BytesUsed())
+	}
+}
+
+func TestTinyLFU_AdmissionKeepsVictims(t *testing.T) {
+	deleted := make([]string, 0)
+	cache := tinylfu.NewTinyLFUWithCounters(int64(1000), 64, func(key string, value lru2.Value) {
+		deleted = append(deleted, key)
+	})
+	// 主缓存990字节 a和b各占495字节 放不进窗口的键直接交给准入过滤
+	cache.SetValue("a", String(strings.Repeat("x", 494)))
+	for i := 0; i < 5; i++ {
+		cache.GetValue("b")
+	}
+	cache.SetValue("b", String(strings.Repeat("x", 494)))
+
+	// c需要同时挤掉a和b 它比a热门但不如b热门 应该被拒绝 a也不能被淘汰
+	cache.GetValue("c")
+	cache.GetValue("c")
+	cache.SetValue("c", String(strings.Repeat("x", 599)))
+	if len(deleted) != 1 || deleted[0] != "c" {
+		t.Fatalf("only the candidate should be rejected, deleted %v", deleted)
+	}
+	for _, key := range []string{"a", "b"} {
+		if _, isOk := cache.GetValue(key); !isOk {
+			t.Fatalf("victim %s should stay when the candidate is rejected", key)
+		}
+	}
+
+	// 足够热门的候选者挤掉所有受害者 每个受害者都要通知回调函数
+	for i := 0; i < 10; i++ {
+		cache.GetValue("d")
+	}
+	cache.SetValue("d", String(strings.Repeat("x", 599)))
+	if _, isOk := cache.GetValue("d"); !isOk || len(deleted) != 3 {
+		t.Fatalf("hot candidate should be admitted and evict both victims, deleted %v", deleted)
+	}
+}
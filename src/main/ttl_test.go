@@ -0,0 +1,125 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_TTL(t *testing.T) {
+	var loads int32
+	group, _ := misakacache.NewRegistry().NewGroup("ttl", misakacache.TTLGetterFunc(
+		func(_ context.Context, key string) ([]byte, time.Duration, error) {
+			atomic.AddInt32(&loads, 1)
+			if key == "short" {
+				return []byte("value"), 20 * time.Millisecond, nil
+			}
+			return []byte("value"), 0, nil // 使用Group的默认存活时间
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithTTL(time.Hour))
+
+	view, err := group.GetFromCache("short")
+	if err != nil || view.ToString() != "value" || view.Expire().IsZero() {
+		t.Fatalf("load short failed, err %v", err)
+	}
+	group.GetFromCache("short")
+	if atomic.LoadInt32(&loads) != 1 {
+		t.Fatalf("short should be hit before expiring")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	group.GetFromCache("short")
+	if atomic.LoadInt32(&loads) != 2 {
+		t.Fatalf("expired short should be reloaded")
+	}
+
+	view, _ = group.GetFromCache("long")
+	if time.Until(view.Expire()) < 59*time.Minute {
+		t.Fatalf("long should use the default ttl, expire at %v", view.Expire())
+	}
+}
+
+func TestGroup_ExpiryReaper(t *testing.T) {
+	deleted := make(chan string, 10)
+	group, _ := misakacache.NewRegistry().NewGroup("reaper", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("value"), nil
+		}), misakacache.WithCacheBytes(2<<10),
+		misakacache.WithTTL(10*time.Millisecond),
+		misakacache.WithExpiryReaper(5*time.Millisecond, 1),
+		misakacache.WithOnEntryDeleted(func(key string, value misakacache.ByteView, reason misakacache.DeleteReason) {
+			if reason == misakacache.DeleteReasonExpired {
+				deleted <- key
+			}
+		}))
+	defer group.Stop()
+
+	group.GetFromCache("key1")
+	group.GetFromCache("key2")
+
+	expired := make(map[string]bool)
+	for len(expired) < 2 {
+		select {
+		case key := <-deleted:
+			expired[key] = true
+		case <-time.After(time.Second):
+			t.Fatalf("reaper did not remove expired entries, removed %v", expired)
+		}
+	}
+	group.Stop() // 重复调用不应该panic
+}
+
+func TestGroup_ExpiryReaperOverwrite(t *testing.T) {
+	var expired atomic.Int32
+	group, _ := misakacache.NewRegistry().NewGroup("reaper-overwrite", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("value"), nil
+		}), misakacache.WithExpiryReaper(5*time.Millisecond, 10),
+		misakacache.WithOnEntryDeleted(func(key string, value misakacache.ByteView, reason misakacache.DeleteReason) {
+			if reason == misakacache.DeleteReasonExpired {
+				expired.Add(1)
+			}
+		}))
+	defer group.Stop()
+
+	ctx := context.Background()
+	group.Set(ctx, "key", []byte("short"), 10*time.Millisecond)
+	group.Set(ctx, "key", []byte("long"), time.Hour) // 覆盖后旧的过期时间不再生效
+	time.Sleep(40 * time.Millisecond)
+	if view, err := group.Get(ctx, "key"); err != nil || view.ToString() != "long" || expired.Load() != 0 {
+		t.Fatalf("overwritten entry should keep its new expiry, got %s err %v expired %d", view.ToString(), err, expired.Load())
+	}
+}
+
+func TestGroup_StaleWhileRevalidate(t *testing.T) {
+	var version int32
+	group, _ := misakacache.NewRegistry().NewGroup("swr", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte{byte('0' + atomic.AddInt32(&version, 1))}, nil
+		}), misakacache.WithCacheBytes(2<<10),
+		misakacache.WithTTL(20*time.Millisecond),
+		misakacache.WithStaleWhileRevalidate(0, time.Second))
+
+	if view, _ := group.GetFromCache("key"); view.ToString() != "1" {
+		t.Fatalf("first load should return version 1, got %s", view.ToString())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if view, _ := group.GetFromCache("key"); view.ToString() != "1" {
+		t.Fatalf("stale value should be returned right away, got %s", view.ToString())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if view, _ := group.GetFromCache("key"); view.ToString() == "2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stale value was not refreshed in background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if atomic.LoadInt32(&version) != 2 {
+		t.Fatalf("stale value should be refreshed exactly once, loaded %d times", version)
+	}
+}
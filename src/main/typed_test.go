@@ -0,0 +1,73 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"testing"
+)
+
+type user struct {
+	Name  string
+	Score int
+}
+
+// countingCodec 记录解码次数的Codec
+type countingCodec struct {
+	misakacache.JSONCodec[user]
+	decodes int
+}
+
+func (codec *countingCodec) Decode(data []byte) (user, error) {
+	codec.decodes++
+	return codec.JSONCodec.Decode(data)
+}
+
+func TestTypedGroup(t *testing.T) {
+	codec := &countingCodec{}
+	group, _ := misakacache.NewRegistry().NewGroup("typed", misakacache.TypedGetter[user](codec,
+		func(ctx context.Context, key string) (user, error) {
+			return user{Name: key, Score: 630}, nil
+		}), misakacache.WithCacheBytes(2<<10))
+	typed := misakacache.NewTypedGroup[user](group, codec, 1<<10)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if value, err := typed.Get(ctx, "Tom"); err != nil || value != (user{"Tom", 630}) {
+			t.Fatalf("failed to get Tom, got %v err %v", value, err)
+		}
+	}
+	if codec.decodes != 1 {
+		t.Fatalf("cached value should be decoded once, decoded %d times", codec.decodes)
+	}
+
+	typed.Set(ctx, "Tom", user{"Tom", 589}, 0)
+	if value, _ := typed.Get(ctx, "Tom"); value.Score != 589 || codec.decodes != 2 {
+		t.Fatalf("updated value should be decoded again, got %v decoded %d times", value, codec.decodes)
+	}
+
+	values, err := typed.GetMany(ctx, []string{"Tom", "Jack"})
+	if err != nil || len(values) != 2 || values["Jack"].Score != 630 {
+		t.Fatalf("failed to get many, got %v err %v", values, err)
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	gob := misakacache.GobCodec[user]{}
+	data, err := gob.Encode(user{"Sam", 567})
+	if err != nil {
+		t.Fatalf("gob encode failed, err %v", err)
+	}
+	if value, err := gob.Decode(data); err != nil || value != (user{"Sam", 567}) {
+		t.Fatalf("gob decode failed, got %v err %v", value, err)
+	}
+
+	protoCodec := misakacache.ProtoCodec[*pb.Response]{New: func() *pb.Response { return &pb.Response{} }}
+	data, err = protoCodec.Encode(&pb.Response{Value: []byte("567")})
+	if err != nil {
+		t.Fatalf("proto encode failed, err %v", err)
+	}
+	if value, err := protoCodec.Decode(data); err != nil || string(value.GetValue()) != "567" {
+		t.Fatalf("proto decode failed, err %v", err)
+	}
+}
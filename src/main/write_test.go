@@ -0,0 +1,120 @@
+package main
+
+import (
+	"MisakaCache/src/misakacache"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_SetRemoveInvalidate(t *testing.T) {
+	loadCounts := make(map[string]int)
+	group, _ := misakacache.NewRegistry().NewGroup("write", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			loadCounts[key]++
+			return []byte("db"), nil
+		}), misakacache.WithCacheBytes(2<<10))
+	ctx := context.Background()
+
+	if err := group.Set(ctx, "Tom", []byte("630"), 0); err != nil {
+		t.Fatalf("failed to set Tom, err %v", err)
+	}
+	if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "630" || loadCounts["Tom"] != 0 {
+		t.Fatalf("Set value should be served without calling getter, got %s loaded %d", view.ToString(), loadCounts["Tom"])
+	}
+
+	group.Remove(ctx, "Tom")
+	if view, _ := group.Get(ctx, "Tom"); view.ToString() != "db" || loadCounts["Tom"] != 1 {
+		t.Fatalf("removed key should be reloaded, got %s loaded %d", view.ToString(), loadCounts["Tom"])
+	}
+
+	group.Invalidate(ctx, "Tom")
+	if view, _ := group.Get(ctx, "Tom"); view.ToString() != "db" || loadCounts["Tom"] != 2 {
+		t.Fatalf("invalidated key should be reloaded, loaded %d", loadCounts["Tom"])
+	}
+
+	group.Set(ctx, "Jack", []byte("589"), 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if view, _ := group.Get(ctx, "Jack"); view.ToString() != "db" {
+		t.Fatalf("Set value should expire after ttl, got %s", view.ToString())
+	}
+}
+
+func TestGroup_SetRoutedToPeer(t *testing.T) {
+	deleted := make(chan misakacache.DeleteReason, 1)
+	registry := misakacache.NewRegistry()
+	group, _ := registry.NewGroup("write-peer", misakacache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return []byte("db"), nil
+		}), misakacache.WithCacheBytes(2<<10), misakacache.WithOnEntryDeleted(func(key string, value misakacache.ByteView, reason misakacache.DeleteReason) {
+		deleted <- reason
+	}))
+
+	// 服务端和客户端共用同一个Group 客户端的所有key都属于服务端 写入经过HTTP后落在同一个缓存里
+	server := httptest.NewServer(registry.NewHTTPPool("server"))
+	defer server.Close()
+	pool := registry.NewHTTPPool("client")
+	pool.SetNewPeer(server.URL)
+	group.RegisterPeers(pool)
+
+	ctx := context.Background()
+	if err := group.Set(ctx, "Tom", []byte("630"), 0); err != nil {
+		t.Fatalf("failed to set Tom on peer, err %v", err)
+	}
+	if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "630" {
+		t.Fatalf("value set on peer should be cached, got %s err %v", view.ToString(), err)
+	}
+
+	if err := group.Remove(ctx, "Tom"); err != nil {
+		t.Fatalf("failed to remove Tom on peer, err %v", err)
+	}
+	select {
+	case reason := <-deleted:
+		if reason != misakacache.DeleteReasonRemoved {
+			t.Fatalf("expected reason removed, got %v", reason)
+		}
+	default:
+		t.Fatalf("Tom should be removed on peer")
+	}
+}
+
+func TestGroup_WriteAfterFallback(t *testing.T) {
+	var down atomic.Bool
+	server := newPeerServer("write-fallback", func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if down.Load() {
+				http.Error(w, "down", http.StatusServiceUnavailable)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+	defer server.Close()
+	group := newClientGroup("write-fallback", server.URL)
+	ctx := context.Background()
+
+	// 远程节点不可用时从本地加载的值也要在写入之后失效
+	down.Store(true)
+	for _, key := range []string{"Tom", "Jack"} {
+		if view, err := group.Get(ctx, key); err != nil || view.ToString() != "client:"+key {
+			t.Fatalf("unavailable peer should fall back to local, got %s err %v", view.ToString(), err)
+		}
+	}
+	down.Store(false)
+
+	if err := group.Set(ctx, "Tom", []byte("630"), 0); err != nil {
+		t.Fatalf("failed to set Tom on peer, err %v", err)
+	}
+	if view, err := group.Get(ctx, "Tom"); err != nil || view.ToString() != "630" {
+		t.Fatalf("local copy should be dropped after Set, got %s err %v", view.ToString(), err)
+	}
+	if err := group.Remove(ctx, "Jack"); err != nil {
+		t.Fatalf("failed to remove Jack on peer, err %v", err)
+	}
+	if view, err := group.Get(ctx, "Jack"); err != nil || view.ToString() != "server:Jack" {
+		t.Fatalf("local copy should be dropped after Remove, got %s err %v", view.ToString(), err)
+	}
+}
@@ -0,0 +1,231 @@
+package arc
+
+import (
+	"MisakaCache/src/misakacache/lru"
+	"container/list"
+)
+
+/*
+ARC算法 Adaptive Replacement Cache 自适应替换缓存 同时兼顾了LRU的"最近"和LFU的"频繁"
+
+ARC维护四条链表：
+T1 只被访问过一次的缓存 按LRU排列
+T2 被访问过至少两次的缓存 按LRU排列
+B1 从T1淘汰出去的缓存的"幽灵" 只记录键和大小 不保存值
+B2 从T2淘汰出去的缓存的"幽灵"
+
+另外维护一个目标值p 表示T1期望占用的大小 淘汰时T1超过p就从T1淘汰 否则从T2淘汰
+当一个键在B1中命中 说明T1太小了 增大p；在B2中命中 说明T2太小了 减小p
+这样ARC不需要任何手动调参 就能根据访问模式自己调整 并且一次性的扫描只会进入T1 不会冲掉T2里的热点缓存
+
+原论文中容量按条目数计算 这里为了和LRU保持一致 容量、p以及各链表的大小都按占用的内存字节数计算
+*/
+
+// 四条链表的标识
+const (
+	listT1 = iota
+	listT2
+	listB1
+	listB2
+)
+
+// ARC 采用了ARC策略的缓存类
+type ARC struct {
+	maxMemoryBytes int64                             // 允许使用的最大内存 即T1+T2的上限
+	target         int64                             // 目标值p T1期望占用的内存
+	lists          [4]*list.List                     // T1 T2 B1 B2
+	listBytes      [4]int64                          // 四条链表各自占用的内存 幽灵链表记录的是被淘汰时的大小
+	cacheMap       map[string]*list.Element          // 字典 同时索引四条链表中的元素
+	OnEntryDeleted func(key string, value lru.Value) // 当缓存被删除时的回调函数 这种函数类型的默认值就是nil
+}
+
+// NewARC ARC的构造函数
+func NewARC(maxMemoryBytes int64, onEntryDeleted func(string, lru.Value)) (cache *ARC) {
+	cache = &ARC{
+		maxMemoryBytes: maxMemoryBytes,
+		cacheMap:       make(map[string]*list.Element),
+		OnEntryDeleted: onEntryDeleted,
+	}
+	for i := range cache.lists {
+		cache.lists[i] = list.New()
+	}
+	return
+}
+
+// entry 链表中所存储的类型 幽灵链表中的entry的value为nil
+type entry struct {
+	key   string
+	value lru.Value
+	size  int64 // 键和值一共占用的内存
+	where int   // 所在的链表
+}
+
+// GetValue 在缓存里查找值 命中的缓存会被移动到T2
+func (cache *ARC) GetValue(key string) (value lru.Value, isOk bool) {
+	element, isOk := cache.cacheMap[key]
+	if !isOk {
+		return nil, false
+	}
+	cacheEntry := element.Value.(*entry)
+	if cacheEntry.where != listT1 && cacheEntry.where != listT2 { // 幽灵不算命中
+		return nil, false
+	}
+	cache.moveTo(element, listT2)
+	return cacheEntry.value, true
+}
+
+// SetValue 添加/修改缓存
+func (cache *ARC) SetValue(key string, value lru.Value) {
+	size := int64(len(key)) + int64(value.GetMemoryUsed())
+
+	if element, isOk := cache.cacheMap[key]; isOk {
+		cacheEntry := element.Value.(*entry)
+		switch cacheEntry.where {
+		case listT1, listT2: // 缓存存在 视为一次访问
+			cache.listBytes[cacheEntry.where] += size - cacheEntry.size
+			cacheEntry.value, cacheEntry.size = value, size
+			cache.moveTo(element, listT2)
+			cache.replace(false)
+			return
+		case listB1: // 在B1中命中 说明T1太小了
+			delta := size
+			if cache.listBytes[listB1] > 0 && cache.listBytes[listB2] > cache.listBytes[listB1] {
+				delta = size * cache.listBytes[listB2] / cache.listBytes[listB1]
+			}
+			cache.target = min(cache.maxMemoryBytes, cache.target+delta)
+			cache.reviveGhost(element, value, size)
+			cache.replace(false)
+			return
+		case listB2: // 在B2中命中 说明T2太小了
+			delta := size
+			if cache.listBytes[listB2] > 0 && cache.listBytes[listB1] > cache.listBytes[listB2] {
+				delta = size * cache.listBytes[listB1] / cache.listBytes[listB2]
+			}
+			cache.target = max(0, cache.target-delta)
+			cache.reviveGhost(element, value, size)
+			cache.replace(true)
+			return
+		}
+	}
+
+	// 完全的新键 放进T1
+	cache.cacheMap[key] = cache.lists[listT1].PushFront(&entry{key: key, value: value, size: size, where: listT1})
+	cache.listBytes[listT1] += size
+	cache.replace(false)
+}
+
+// RemoveOldestCache 按ARC的规则淘汰一次缓存
+func (cache *ARC) RemoveOldestCache() {
+	cache.evict(false)
+	cache.trimGhosts()
+}
+
+// RemoveValue 根据键删除一条缓存 返回该键是否存在 主动删除不会触发OnEntryDeleted回调 也不会留下幽灵
+func (cache *ARC) RemoveValue(key string) bool {
+	element, isOk := cache.cacheMap[key]
+	if !isOk {
+		return false
+	}
+	cacheEntry := element.Value.(*entry)
+	cache.lists[cacheEntry.where].Remove(element)
+	cache.listBytes[cacheEntry.where] -= cacheEntry.size
+	delete(cache.cacheMap, key)
+	return cacheEntry.where == listT1 || cacheEntry.where == listT2
+}
+
+// GetARCEntryNumber 返回当前已缓存的键值对数量 不包括幽灵
+func (cache *ARC) GetARCEntryNumber() int {
+	return cache.lists[listT1].Len() + cache.lists[listT2].Len()
+}
+
+// Len 返回当前已缓存的键值对数量 不包括幽灵
+func (cache *ARC) Len() int {
+	return cache.GetARCEntryNumber()
+}
+
+// BytesUsed 返回当前已经使用的内存 即T1和T2占用的内存之和
+func (cache *ARC) BytesUsed() int64 {
+	return cache.listBytes[listT1] + cache.listBytes[listT2]
+}
+
+// OnEvict 设置缓存被淘汰时的回调函数
+func (cache *ARC) OnEvict(onEntryDeleted func(key string, value lru.Value)) {
+	cache.OnEntryDeleted = onEntryDeleted
+}
+
+// GetTarget 返回当前的目标值p 用于观察ARC的自适应情况
+func (cache *ARC) GetTarget() int64 {
+	return cache.target
+}
+
+// moveTo 把元素移动到指定链表的队尾（Front）
+func (cache *ARC) moveTo(element *list.Element, where int) {
+	cacheEntry := element.Value.(*entry)
+	if cacheEntry.where == where {
+		cache.lists[where].MoveToFront(element)
+		return
+	}
+	cache.lists[cacheEntry.where].Remove(element)
+	cache.listBytes[cacheEntry.where] -= cacheEntry.size
+	cacheEntry.where = where
+	cache.cacheMap[cacheEntry.key] = cache.lists[where].PushFront(cacheEntry)
+	cache.listBytes[where] += cacheEntry.size
+}
+
+// reviveGhost 幽灵命中 把值放回去并移动到T2
+func (cache *ARC) reviveGhost(element *list.Element, value lru.Value, size int64) {
+	cacheEntry := element.Value.(*entry)
+	cache.listBytes[cacheEntry.where] -= cacheEntry.size // 幽灵记录的是旧的大小 先按旧的大小扣除
+	cacheEntry.value, cacheEntry.size = value, size
+	cache.listBytes[cacheEntry.where] += size
+	cache.moveTo(element, listT2)
+}
+
+// replace 内存超出上限时不断淘汰缓存 hitB2表示本次操作是否是B2中的幽灵命中
+func (cache *ARC) replace(hitB2 bool) {
+	for cache.BytesUsed() > cache.maxMemoryBytes && cache.BytesUsed() != 0 {
+		cache.evict(hitB2)
+	}
+	cache.trimGhosts()
+}
+
+// evict 淘汰一条缓存 T1超过目标值时从T1淘汰 否则从T2淘汰 被淘汰的缓存变成幽灵进入对应的幽灵链表
+func (cache *ARC) evict(hitB2 bool) {
+	t1Bytes := cache.listBytes[listT1]
+	from, to := listT2, listB2
+	if cache.lists[listT1].Len() > 0 &&
+		(t1Bytes > cache.target || (hitB2 && t1Bytes == cache.target) || cache.lists[listT2].Len() == 0) {
+		from, to = listT1, listB1
+	}
+
+	element := cache.lists[from].Back()
+	if element == nil {
+		return
+	}
+	cacheEntry := element.Value.(*entry)
+	value := cacheEntry.value
+	cache.moveTo(element, to)
+	cacheEntry.value = nil // 幽灵不保存值
+	if cache.OnEntryDeleted != nil {
+		cache.OnEntryDeleted(cacheEntry.key, value)
+	}
+}
+
+// trimGhosts 限制幽灵链表的大小 T1+B1不超过容量 四条链表之和不超过两倍容量
+func (cache *ARC) trimGhosts() {
+	for cache.listBytes[listT1]+cache.listBytes[listB1] > cache.maxMemoryBytes && cache.lists[listB1].Len() > 0 {
+		cache.dropGhost(listB1)
+	}
+	for cache.BytesUsed()+cache.listBytes[listB1]+cache.listBytes[listB2] > 2*cache.maxMemoryBytes && cache.lists[listB2].Len() > 0 {
+		cache.dropGhost(listB2)
+	}
+}
+
+// dropGhost 彻底删除幽灵链表中最旧的幽灵
+func (cache *ARC) dropGhost(where int) {
+	element := cache.lists[where].Back()
+	cacheEntry := element.Value.(*entry)
+	cache.lists[where].Remove(element)
+	cache.listBytes[where] -= cacheEntry.size
+	delete(cache.cacheMap, cacheEntry.key)
+}
@@ -0,0 +1,245 @@
+package misakacache
+
+import (
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GetMany 一次获取多个key的缓存值 返回的map中只包含存在的key 重复的key只会加载一次
+// 本地未命中的key按所属的远程节点分组 每个远程节点只发送一次批量请求
+// 属于本节点的key 如果getter实现了BatchGetter 则通过一次GetBatch加载
+// 部分key加载失败时 仍然返回其他key的值 同时返回遇到的第一个错误
+func (g *Group) GetMany(ctx context.Context, keys []string) (map[string]ByteView, error) {
+	result := &batchResult{values: make(map[string]ByteView, len(keys))}
+	var (
+		local  []string
+		remote = make(map[PeerCacheValueGetter][]string) // 按远程节点分组的key
+		seen   = make(map[string]struct{}, len(keys))
+	)
+	for _, key := range keys {
+		if _, ok := seen[key]; ok || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		g.record(EventGet, 0)
+		if v, isOk := g.lookupCache(key); isOk { // 缓存命中 负缓存命中的key直接跳过
+			if !v.notFound {
+				result.values[key] = v
+			}
+			continue
+		}
+		if g.peers != nil {
+			if peer, ok := g.peers.PickPeer(key); ok {
+				remote[peer] = append(remote[peer], key)
+				continue
+			}
+		}
+		local = append(local, key)
+	}
+
+	var wg sync.WaitGroup
+	for peer, peerKeys := range remote { // 每个远程节点一个协程 互不等待
+		wg.Add(1)
+		go func(peer PeerCacheValueGetter, peerKeys []string) {
+			defer wg.Done()
+			g.getManyFromPeer(ctx, peer, peerKeys, result)
+		}(peer, peerKeys)
+	}
+	g.getManyFromLocal(ctx, local, result)
+	wg.Wait()
+	return result.values, result.err
+}
+
+// getManyFromPeer 从一个远程节点批量获取缓存 远程节点不支持批量请求时逐个key请求 熔断器打开或者批量请求失败时调用fallbackMany
+func (g *Group) getManyFromPeer(ctx context.Context, peer PeerCacheValueGetter, keys []string, result *batchResult) {
+	batchPeer, ok := peer.(PeerBatchGetter)
+	if !ok {
+		g.loadEach(ctx, keys, result, g.load)
+		return
+	}
+
+	breaker := g.breaker(peer)
+	if breaker != nil && !breaker.allow(time.Now()) {
+		g.record(EventPeerRejected, 0)
+		g.fallbackMany(ctx, keys, errBreakerOpen, result)
+		return
+	}
+
+	peerCtx, cancel := g.loaderContext(ctx)
+	defer cancel()
+	resp := &pb.BatchResponse{}
+	start := time.Now()
+	err := batchPeer.GetManyFromPeer(peerCtx, &pb.BatchRequest{Group: g.name, Keys: keys}, resp)
+	g.breakerDone(ctx, breaker, err)
+	latency := time.Since(start)
+	if err == nil {
+		g.recordEach(EventPeerLoad, latency, len(keys))
+		for _, kv := range resp.GetValues() { // 响应中没有的key就是不存在的key
+			value := ByteView{cacheBytes: kv.GetValue()}
+			g.populateHotCache(kv.GetKey(), value)
+			result.set(kv.GetKey(), value)
+		}
+		return
+	}
+	g.recordEach(EventPeerError, latency, len(keys))
+	if ctx.Err() != nil { // 调用方已经不再等待 没有必要再从本地加载
+		result.fail(ctx.Err())
+		return
+	}
+	if !shouldFallback(err) { // 请求本身有问题 从本地加载也一样
+		result.fail(err)
+		return
+	}
+	g.logger.Printf("[MisakaCache] Failed to get many from peer: %v", err)
+	g.fallbackMany(ctx, keys, err, result)
+}
+
+// fallbackMany 远程节点的熔断器打开或者批量请求失败时 按WithPeerFallback指定的方式加载这些key
+func (g *Group) fallbackMany(ctx context.Context, keys []string, err error, result *batchResult) {
+	switch {
+	case g.peerFallback == FallbackError:
+		result.fail(err)
+	case g.peerFallback == FallbackNextPeer && errors.Is(err, errBreakerOpen):
+		g.loadEach(ctx, keys, result, g.load) // 逐个key加载 熔断的节点会被跳过 请求环上的下一个节点
+	default: // 批量请求失败后不再逐个key重试该节点 直接从本地加载
+		g.getManyFromLocal(ctx, keys, result)
+	}
+}
+
+// getManyFromLocal 从本地批量加载缓存 getter没有实现BatchGetter或者实现了TTLGetter时逐个key加载
+// 每个key都要先在singleflight中登记 已经在加载中的key等待别人的结果 其余的key按maxBatchSize分批调用GetBatch
+func (g *Group) getManyFromLocal(ctx context.Context, keys []string, result *batchResult) {
+	if len(keys) == 0 {
+		return
+	}
+	batchGetter, ok := g.getter.(BatchGetter)
+	if _, isTTLGetter := g.getter.(TTLGetter); !ok || isTTLGetter { // GetBatch无法返回每个key的存活时间
+		g.loadEach(ctx, keys, result, g.loadLocal)
+		return
+	}
+
+	var (
+		owned    []string
+		inFlight []string
+		finishes = make(map[string]func(interface{}, error), len(keys))
+	)
+	for _, key := range keys {
+		if finish, ok := g.loader.Acquire(key); ok {
+			owned = append(owned, key)
+			finishes[key] = finish
+		} else { // 别的调用方正在加载 等待它的结果
+			inFlight = append(inFlight, key)
+		}
+	}
+
+	maxBatchSize := g.maxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultMaxBatchSize
+	}
+	var wg sync.WaitGroup
+	for len(owned) > 0 {
+		chunk := owned[:min(maxBatchSize, len(owned))]
+		owned = owned[len(chunk):]
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+			g.loadBatch(ctx, batchGetter, chunk, finishes, result)
+		}(chunk)
+	}
+	g.loadEach(ctx, inFlight, result, g.loadLocal)
+	wg.Wait()
+}
+
+// loadBatch 通过一次GetBatch加载一批已经在singleflight中登记过的key 并通过finishes发布每个key的结果
+func (g *Group) loadBatch(ctx context.Context, batchGetter BatchGetter, keys []string, finishes map[string]func(interface{}, error), result *batchResult) {
+	loadCtx, cancel := g.loaderContext(ctx)
+	defer cancel()
+	start := time.Now()
+	values, err := batchGetter.GetBatch(loadCtx, keys)
+	latency := time.Since(start)
+	if err != nil {
+		g.recordEach(EventLoadError, latency, len(keys))
+	} else {
+		g.recordEach(EventLocalLoad, latency, len(keys))
+	}
+	for _, key := range keys {
+		if err != nil {
+			finishes[key](nil, err)
+			continue
+		}
+		bytes, ok := values[key]
+		if !ok { // 数据源中不存在
+			if g.negativeTTL > 0 {
+				g.populateCache(key, ByteView{notFound: true, expire: expireAfter(g.negativeTTL)})
+			}
+			finishes[key](nil, fmt.Errorf("%s: %w", key, ErrNotFound))
+			continue
+		}
+		value := ByteView{cacheBytes: cloneBytes(bytes), expire: expireAfter(g.defaultTTL)}
+		g.populateCache(key, value)
+		result.set(key, value)
+		finishes[key](value, nil)
+	}
+	if err != nil {
+		result.fail(err)
+	}
+}
+
+// loadEach 并发地逐个key调用load 用于不支持批量请求的远程节点和getter
+func (g *Group) loadEach(ctx context.Context, keys []string, result *batchResult, load func(ctx context.Context, key string) (ByteView, error)) {
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			value, err := load(ctx, key)
+			if err != nil {
+				result.fail(err)
+				return
+			}
+			result.set(key, value)
+		}(key)
+	}
+	wg.Wait()
+}
+
+// loadLocal 只从本地加载缓存 不再挑选远程节点 和load共用singleflight
+func (g *Group) loadLocal(ctx context.Context, key string) (ByteView, error) {
+	viewi, err := g.loader.DoContext(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return g.getFromLocal(ctx, key)
+	})
+	if err != nil {
+		return ByteView{}, err
+	}
+	return viewi.(ByteView), nil
+}
+
+// batchResult GetMany的结果 多个协程会同时写入
+type batchResult struct {
+	mu     sync.Mutex
+	values map[string]ByteView
+	err    error // 遇到的第一个错误 ErrNotFound不算错误
+}
+
+// set 写入一个key的值
+func (r *batchResult) set(key string, value ByteView) {
+	r.mu.Lock()
+	r.values[key] = value
+	r.mu.Unlock()
+}
+
+// fail 记录一个错误 只保留第一个
+func (r *batchResult) fail(err error) {
+	if errors.Is(err, ErrNotFound) {
+		return
+	}
+	r.mu.Lock()
+	if r.err == nil {
+		r.err = err
+	}
+	r.mu.Unlock()
+}
@@ -0,0 +1,93 @@
+package misakacache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultMaxBatchSize = 100 // 一次GetBatch默认最多加载的key数量
+
+// batcher 把一段时间窗口内并发到达的未命中合并成一次GetBatch
+// 同一个key的并发未命中已经被singleflight合并 所以进入batcher的都是不同的key
+type batcher struct {
+	getter   BatchGetter
+	window   time.Duration // 第一个key到达后最多等待多久
+	maxBatch int           // 攒够这么多key之后立刻发出 不再等待窗口结束
+	timeout  time.Duration // 一次GetBatch的超时时间 为0时不限制
+
+	mu      sync.Mutex
+	pending *pendingBatch // 正在攒的一批key 为nil时下一个key会开启新的一批
+}
+
+// pendingBatch 一批等待加载的key
+type pendingBatch struct {
+	keys   []string
+	done   chan struct{} // 加载完成后关闭
+	values map[string][]byte
+	err    error
+}
+
+// newBatcher batcher的构造函数 maxBatch不为正数时使用默认值
+func newBatcher(getter BatchGetter, window time.Duration, maxBatch int, timeout time.Duration) *batcher {
+	if maxBatch <= 0 {
+		maxBatch = defaultMaxBatchSize
+	}
+	return &batcher{getter: getter, window: window, maxBatch: maxBatch, timeout: timeout}
+}
+
+// get 把key加入当前的一批 等待这一批加载完成 ctx结束时不再等待
+func (b *batcher) get(ctx context.Context, key string) ([]byte, error) {
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil { // 开启新的一批 窗口结束时发出
+		batch = &pendingBatch{done: make(chan struct{})}
+		b.pending = batch
+		time.AfterFunc(b.window, func() { b.flush(batch) })
+	}
+	batch.keys = append(batch.keys, key)
+	if len(batch.keys) >= b.maxBatch { // 攒够了 立刻发出
+		b.pending = nil
+		go b.run(batch)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	bytes, ok := batch.values[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return bytes, nil
+}
+
+// flush 窗口结束 如果这一批还没有因为攒够而发出 则发出
+func (b *batcher) flush(batch *pendingBatch) {
+	b.mu.Lock()
+	if b.pending != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+	b.run(batch)
+}
+
+// run 实际调用GetBatch 这一批属于多个调用方 所以使用context.Background()
+func (b *batcher) run(batch *pendingBatch) {
+	ctx := context.Background()
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	batch.values, batch.err = b.getter.GetBatch(ctx, batch.keys)
+	close(batch.done)
+}
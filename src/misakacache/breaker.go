@@ -0,0 +1,294 @@
+package misakacache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errBreakerOpen 远程节点的熔断器处于打开状态 请求没有发出
+var errBreakerOpen = fmt.Errorf("peer circuit breaker is open: %w", ErrUnavailable)
+
+// BreakerState 熔断器的状态
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 正常请求远程节点 同时统计错误率
+	BreakerOpen                         // 错误率过高 不再请求该远程节点
+	BreakerHalfOpen                     // 打开一段时间后 放行少量请求试探远程节点是否恢复
+)
+
+// String 返回熔断器状态的字符串表示
+func (state BreakerState) String() string {
+	switch state {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig 熔断器的配置 为0的字段使用默认值
+// 只有请求失败且可以换一个地方加载的错误（例如超时、连接失败、远程节点不可用）才算作失败
+// ErrNotFound和ErrBadRequest说明远程节点是正常的
+type BreakerConfig struct {
+	Disabled         bool          // 为true时不使用熔断器 每次都请求远程节点
+	Window           time.Duration // 统计错误率的时间窗口 默认10s
+	MinRequests      int           // 窗口内的请求数达到MinRequests后才会根据错误率打开 默认20
+	ErrorRate        float64       // 窗口内的错误率达到ErrorRate时打开 默认0.5
+	OpenTimeout      time.Duration // 打开状态持续的时间 之后进入半开状态 默认5s
+	HalfOpenRequests int           // 半开状态下放行的请求数 全部成功后关闭 任意一个失败重新打开 默认1
+}
+
+// defaultBreakerConfig 熔断器的默认配置
+var defaultBreakerConfig = BreakerConfig{
+	Window:           10 * time.Second,
+	MinRequests:      20,
+	ErrorRate:        0.5,
+	OpenTimeout:      5 * time.Second,
+	HalfOpenRequests: 1,
+}
+
+// withDefaults 用默认值补全为0的字段
+func (config BreakerConfig) withDefaults() BreakerConfig {
+	if config.Window <= 0 {
+		config.Window = defaultBreakerConfig.Window
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaultBreakerConfig.MinRequests
+	}
+	if config.ErrorRate <= 0 {
+		config.ErrorRate = defaultBreakerConfig.ErrorRate
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaultBreakerConfig.OpenTimeout
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = defaultBreakerConfig.HalfOpenRequests
+	}
+	return config
+}
+
+// PeerFallback 远程节点的熔断器打开或者请求失败时 key的加载方式
+type PeerFallback int
+
+const (
+	FallbackLocal    PeerFallback = iota // 调用getter从本地加载 默认
+	FallbackNextPeer                     // 请求一致性哈希环上的下一个节点 下一个节点是自身或者同样失败时从本地加载
+	FallbackError                        // 直接返回错误 熔断器打开时返回的错误可以用errors.Is判断ErrUnavailable
+)
+
+// String 返回加载方式的字符串表示
+func (fallback PeerFallback) String() string {
+	switch fallback {
+	case FallbackLocal:
+		return "local"
+	case FallbackNextPeer:
+		return "next-peer"
+	case FallbackError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCircuitBreaker 设置每个远程节点的熔断器 默认开启 config.Disabled为true时关闭
+// 熔断器打开后 请求不再发往该远程节点 而是按WithPeerFallback指定的方式加载 避免每次请求都等到超时
+func WithCircuitBreaker(config BreakerConfig) GroupOption {
+	return func(group *Group) {
+		group.breakerConfig = config.withDefaults()
+	}
+}
+
+// WithPeerFallback 设置远程节点的熔断器打开或者请求失败时key的加载方式 默认为FallbackLocal
+// key不存在和请求本身有问题时不会触发 直接返回远程节点的错误
+func WithPeerFallback(fallback PeerFallback) GroupOption {
+	return func(group *Group) {
+		group.peerFallback = fallback
+	}
+}
+
+// circuitBreaker 一个远程节点的熔断器
+type circuitBreaker struct {
+	config BreakerConfig
+
+	mu          sync.Mutex
+	state       BreakerState
+	windowStart time.Time // 当前统计窗口的开始时间
+	requests    int       // 当前窗口内的请求数
+	failures    int       // 当前窗口内失败的请求数
+	openedAt    time.Time // 最近一次打开的时间
+	probes      int       // 半开状态下已经放行的请求数
+	successes   int       // 半开状态下成功的请求数
+}
+
+// newCircuitBreaker circuitBreaker的构造函数
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{config: config}
+}
+
+// allow 判断现在能否请求该远程节点 返回true时调用方需要在请求结束后调用done或者cancel
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probes, b.successes = 0, 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+		return true
+	default:
+		return true
+	}
+}
+
+// done 记录一次请求的结果 并根据结果切换状态
+func (b *circuitBreaker) done(failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case BreakerHalfOpen:
+		if failed {
+			b.open(now)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.state = BreakerClosed
+			b.resetWindow(now)
+		}
+	case BreakerClosed:
+		if now.Sub(b.windowStart) > b.config.Window {
+			b.resetWindow(now)
+		}
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.config.MinRequests && float64(b.failures) >= b.config.ErrorRate*float64(b.requests) {
+			b.open(now)
+		}
+	}
+	// 打开状态下结束的请求是在打开之前发出的 不影响状态
+}
+
+// cancel 请求被调用方取消 结果不能说明远程节点是否正常 归还半开状态下占用的名额
+func (b *circuitBreaker) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// open 切换到打开状态 调用方需要持有锁
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+}
+
+// resetWindow 开始新的统计窗口 调用方需要持有锁
+func (b *circuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+}
+
+// currentState 返回熔断器当前的状态 打开状态超过OpenTimeout后视为半开
+func (b *circuitBreaker) currentState(now time.Time) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// breaker 返回远程节点对应的熔断器 没有开启熔断器时返回nil
+func (g *Group) breaker(peer PeerCacheValueGetter) *circuitBreaker {
+	if g.breakerConfig.Disabled {
+		return nil
+	}
+	g.pruneBreakers()
+	if b, ok := g.breakers.Load(peer); ok {
+		return b.(*circuitBreaker)
+	}
+	b, _ := g.breakers.LoadOrStore(peer, newCircuitBreaker(g.breakerConfig))
+	return b.(*circuitBreaker)
+}
+
+// pruneBreakers 远程节点更新后 删除已经被移除的远程节点的熔断器
+// 只有实现了PeerLister的PeerPicker才能知道哪些节点被移除了
+func (g *Group) pruneBreakers() {
+	lister, ok := g.peers.(PeerLister)
+	if !ok {
+		return
+	}
+	version := lister.PeersVersion()
+	if g.breakersVersion.Swap(version) == version {
+		return
+	}
+	current := make(map[PeerCacheValueGetter]struct{})
+	for _, peer := range lister.Peers() {
+		current[peer] = struct{}{}
+	}
+	g.breakers.Range(func(peer, _ any) bool {
+		if _, ok := current[peer.(PeerCacheValueGetter)]; !ok {
+			g.breakers.Delete(peer)
+		}
+		return true
+	})
+}
+
+// breakerDone 把一次远程请求的结果交给熔断器 被调用方取消的请求不计入
+func (g *Group) breakerDone(ctx context.Context, breaker *circuitBreaker, err error) {
+	if breaker == nil {
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		breaker.cancel()
+		return
+	}
+	breaker.done(err != nil && shouldFallback(err), time.Now())
+}
+
+// pickPeers 挑选key可以请求的远程节点 FallbackNextPeer时还包括环上的下一个节点
+func (g *Group) pickPeers(key string) []PeerCacheValueGetter {
+	if g.peers == nil {
+		return nil
+	}
+	if !g.breakerConfig.Disabled { // 没有选出远程节点时也清理 否则被移除的节点要等到下次请求远程节点时才清理
+		g.pruneBreakers()
+	}
+	if listPicker, ok := g.peers.(PeerListPicker); ok && g.peerFallback == FallbackNextPeer {
+		return listPicker.PickPeers(key, 2)
+	}
+	if peer, ok := g.peers.PickPeer(key); ok {
+		return []PeerCacheValueGetter{peer}
+	}
+	return nil
+}
+
+// PeerBreakerState 返回key所属的远程节点的熔断器状态 key属于本节点或者没有开启熔断器时ok为false
+func (g *Group) PeerBreakerState(key string) (state BreakerState, ok bool) {
+	if g.peers == nil || g.breakerConfig.Disabled {
+		return BreakerClosed, false
+	}
+	peer, ok := g.peers.PickPeer(key)
+	if !ok {
+		return BreakerClosed, false
+	}
+	return g.breaker(peer).currentState(time.Now()), true
+}
@@ -1,8 +1,12 @@
 package misakacache
 
+import "time"
+
 // ByteView 只读数据结构 实现了Value接口 用于表示缓存的值 如果想要获取当前缓存的值 一律从GetByteCopy获取
 type ByteView struct {
 	cacheBytes []byte
+	expire     time.Time // 过期时间 零值表示永不过期
+	notFound   bool      // 负缓存 表示该key在数据源中不存在
 }
 
 // GetMemoryUsed 实现Value接口的方法 返回该缓存值的长度/占用内存多少
@@ -20,6 +24,24 @@ func (view ByteView) ToString() string {
 	return string(view.cacheBytes)
 }
 
+// Expire 返回当前缓存的过期时间 零值表示永不过期
+func (view ByteView) Expire() time.Time {
+	return view.expire
+}
+
+// isExpired 当前缓存在now时刻是否已经过期
+func (view ByteView) isExpired(now time.Time) bool {
+	return !view.expire.IsZero() && !now.Before(view.expire)
+}
+
+// expireAfter 根据ttl计算过期时间 ttl不为正数时表示永不过期
+func expireAfter(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
 func cloneBytes(b []byte) []byte {
 	clone := make([]byte, len(b))
 	copy(clone, b)
@@ -2,36 +2,306 @@ package misakacache
 
 import (
 	"MisakaCache/src/misakacache/lru"
+	"container/heap"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// cache 对LRU的一次封装 并且追加并发保护
+// DeleteReason 缓存被删除的原因
+type DeleteReason int
+
+const (
+	DeleteReasonEvicted DeleteReason = iota // 内存不足 被淘汰策略淘汰
+	DeleteReasonExpired                     // 已经过期
+	DeleteReasonRemoved                     // 通过Group.Remove主动删除
+)
+
+// String 返回删除原因的字符串表示
+func (reason DeleteReason) String() string {
+	switch reason {
+	case DeleteReasonEvicted:
+		return "evicted"
+	case DeleteReasonExpired:
+		return "expired"
+	case DeleteReasonRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// cache 对淘汰策略的一次封装 并且追加并发保护
+// 读取时只需要读锁：items保存了所有缓存的值 命中后把key记录进readBuffer 攒够一批之后再拿写锁统一交给淘汰策略
+// 这样热点读取不再需要每次都拿写锁去移动链表 代价是淘汰策略看到的访问记录是有损的 但对淘汰的效果影响很小
 type cache struct {
-	mutex      sync.Mutex // 互斥锁
-	lru        *lru.LRU   // 封装的LRU
-	cacheBytes int64
+	mutex          sync.RWMutex                                          // 读写锁 读取items只需要读锁 修改items和policy需要写锁
+	policy         EvictionPolicy                                        // 封装的淘汰策略 默认为LRU
+	newPolicy      PolicyFactory                                         // 淘汰策略的构造函数 为nil时使用LRU
+	cacheBytes     int64                                                 // 允许使用的最大内存
+	staleFor       time.Duration                                         // 缓存过期后继续保留的时间 在此期间仍然可以作为旧值返回
+	items          map[string]ByteView                                   // 所有缓存的值 和policy中的缓存保持一致 读取时不经过policy
+	readBuffer     *readBuffer                                           // 命中记录的缓冲区 为nil时每次读取都拿写锁直接交给淘汰策略
+	expireHeap     expireHeap                                            // 按过期时间排序的小根堆 后台清理时从堆顶开始删除 只在后台清理运行时维护
+	expireItems    map[string]*expireItem                                // key到过期堆中记录的索引 缓存被删除或者更新时同步修改过期堆 为nil时不维护过期堆
+	OnEntryDeleted func(key string, value ByteView, reason DeleteReason) // 当缓存被淘汰或过期删除时的回调函数
+
+	gets, hits, evictions atomic.Int64 // 统计数据
+}
+
+// newCache cache的构造函数 淘汰策略仍然在第一次写入时懒加载 bufferedReads决定读取时是否使用命中记录缓冲区
+func newCache(cacheBytes int64, newPolicy PolicyFactory, staleFor time.Duration, bufferedReads bool, onEntryDeleted func(string, ByteView, DeleteReason)) *cache {
+	c := &cache{
+		cacheBytes:     cacheBytes,
+		newPolicy:      newPolicy,
+		staleFor:       staleFor,
+		OnEntryDeleted: onEntryDeleted,
+	}
+	if bufferedReads {
+		c.readBuffer = newReadBuffer()
+	}
+	return c
 }
 
-// add 对LRU.SetValue的封装
+// add 对EvictionPolicy.SetValue的封装
 func (c *cache) add(key string, value ByteView) {
 	c.mutex.Lock() // 互斥锁加锁
 	defer c.mutex.Unlock()
-	if c.lru == nil {
-		c.lru = lru.NewLRU(c.cacheBytes, nil) // 懒加载
+	if c.policy == nil {
+		c.policy = c.createPolicy() // 懒加载
 	}
-	c.lru.SetValue(key, value)
+	c.setExpire(key, value.expire)
+	c.items[key] = value
+	c.policy.SetValue(key, value) // 淘汰策略可能立刻淘汰刚写入的缓存 所以要先写入items
 }
 
-// get 对LRU.GetValue的封装
+// get 读取缓存 过期超过staleFor的缓存会被删除并视为未命中
+// 过期但还在staleFor以内的缓存仍然会被返回 由调用方决定是否刷新
 func (c *cache) get(key string) (value ByteView, isOk bool) {
+	c.gets.Add(1)
+	defer func() {
+		if isOk {
+			c.hits.Add(1)
+		}
+	}()
+	if c.readBuffer == nil {
+		return c.getLocked(key)
+	}
+
+	c.mutex.RLock()
+	value, isOk = c.items[key]
+	c.mutex.RUnlock()
+	if !isOk {
+		return
+	}
+	if !value.expire.IsZero() && value.isExpired(time.Now().Add(-c.staleFor)) { // 惰性删除 需要写锁
+		c.mutex.Lock()
+		if current, ok := c.items[key]; ok && current.expire.Equal(value.expire) { // 拿到写锁之前可能已经被更新了
+			c.remove(key, current, DeleteReasonExpired)
+		}
+		c.mutex.Unlock()
+		return ByteView{}, false
+	}
+	if keys := c.readBuffer.push(key); keys != nil {
+		c.replay(keys)
+	}
+	return
+}
+
+// getLocked 不使用命中记录缓冲区的读取 每次都拿写锁直接交给淘汰策略
+func (c *cache) getLocked(key string) (value ByteView, isOk bool) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	if c.lru == nil {
+	if c.policy == nil {
 		return
 	}
 
-	if v, isOk := c.lru.GetValue(key); isOk {
-		return v.(ByteView), isOk
+	if v, isOk := c.policy.GetValue(key); isOk {
+		value = v.(ByteView)
+		if value.isExpired(time.Now().Add(-c.staleFor)) { // 惰性删除
+			c.remove(key, value, DeleteReasonExpired)
+			return ByteView{}, false
+		}
+		return value, isOk
 	}
 	return
 }
+
+// replay 把一批命中记录交给淘汰策略 每攒够一批才拿一次写锁
+// 不能用TryLock 读取一直持有读锁 并发读取时几乎每一批都会被丢弃 淘汰策略就看不到命中了
+func (c *cache) replay(keys []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, key := range keys {
+		if _, ok := c.items[key]; ok { // 缓冲期间可能已经被淘汰了
+			c.policy.GetValue(key)
+		}
+	}
+}
+
+// createPolicy 根据newPolicy创建淘汰策略 并注册淘汰回调
+func (c *cache) createPolicy() EvictionPolicy {
+	var policy EvictionPolicy
+	if c.newPolicy == nil {
+		policy = LRUPolicy(c.cacheBytes)
+	} else {
+		policy = c.newPolicy(c.cacheBytes)
+	}
+	policy.OnEvict(c.evicted)
+	c.items = make(map[string]ByteView)
+	return policy
+}
+
+// evicted 淘汰策略的回调函数 调用时已经持有锁
+func (c *cache) evicted(key string, value lru.Value) {
+	c.evictions.Add(1)
+	delete(c.items, key)
+	c.setExpire(key, time.Time{})
+	if c.OnEntryDeleted != nil {
+		c.OnEntryDeleted(key, value.(ByteView), DeleteReasonEvicted)
+	}
+}
+
+// remove 主动删除一条缓存并调用回调函数 调用时必须持有锁
+func (c *cache) remove(key string, value ByteView, reason DeleteReason) {
+	c.policy.RemoveValue(key)
+	delete(c.items, key)
+	c.setExpire(key, time.Time{})
+	if c.OnEntryDeleted != nil {
+		c.OnEntryDeleted(key, value, reason)
+	}
+}
+
+// delete 主动删除key对应的缓存 返回该key是否存在
+func (c *cache) delete(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	c.remove(key, value, DeleteReasonRemoved)
+	return true
+}
+
+// invalidate 把key对应的缓存标记为在now时刻过期 返回该key是否存在
+// 缓存不会被立刻删除 在staleFor以内仍然可以作为旧值返回 之后的读取会触发重新加载
+func (c *cache) invalidate(key string, now time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	value, ok := c.items[key]
+	if !ok {
+		return false
+	}
+	if value.isExpired(now) { // 已经过期了 不需要再提前
+		return true
+	}
+	value.expire = now
+	c.setExpire(key, value.expire)
+	c.items[key] = value
+	c.policy.SetValue(key, value)
+	return true
+}
+
+// removeExpired 从过期堆的堆顶开始删除已经过期的缓存 一次最多处理batchSize条记录 返回实际删除的缓存数量
+func (c *cache) removeExpired(now time.Time, batchSize int) (removed int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.policy == nil {
+		return
+	}
+
+	for i := 0; i < batchSize && c.expireHeap.Len() > 0; i++ {
+		item := c.expireHeap[0]
+		if now.Before(item.expire.Add(c.staleFor)) { // 堆顶都没有过期 说明已经没有过期的缓存了
+			break
+		}
+		value := c.items[item.key] // 过期堆和items同步维护 堆中的key一定存在
+		c.remove(item.key, value, DeleteReasonExpired)
+		removed++
+	}
+	return
+}
+
+// trackExpiry 开始或者停止维护过期堆 只有后台清理会使用过期堆 没有后台清理时不维护 避免过期堆无限增长
+func (c *cache) trackExpiry(enabled bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.expireHeap = nil
+	c.expireItems = nil
+	if !enabled {
+		return
+	}
+	c.expireItems = make(map[string]*expireItem)
+	for key, value := range c.items {
+		c.setExpire(key, value.expire)
+	}
+}
+
+// setExpire 更新key在过期堆中的过期时间 expire为零值时从过期堆中移除 调用时必须持有锁
+func (c *cache) setExpire(key string, expire time.Time) {
+	if c.expireItems == nil {
+		return
+	}
+	item, ok := c.expireItems[key]
+	switch {
+	case ok && expire.IsZero():
+		heap.Remove(&c.expireHeap, item.index)
+		delete(c.expireItems, key)
+	case ok:
+		item.expire = expire
+		heap.Fix(&c.expireHeap, item.index)
+	case !expire.IsZero():
+		item = &expireItem{key: key, expire: expire}
+		heap.Push(&c.expireHeap, item)
+		c.expireItems[key] = item
+	}
+}
+
+// stats 返回该缓存的统计数据
+func (c *cache) stats() CacheStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	stats := CacheStats{
+		Gets:      c.gets.Load(),
+		Hits:      c.hits.Load(),
+		Evictions: c.evictions.Load(),
+	}
+	if c.policy != nil {
+		stats.Bytes = c.policy.BytesUsed()
+		stats.Items = int64(c.policy.Len())
+	}
+	return stats
+}
+
+// expireItem 过期堆中的一条记录 每个key最多只有一条
+type expireItem struct {
+	key    string
+	expire time.Time
+	index  int // 在过期堆中的下标 由heap.Interface维护
+}
+
+// expireHeap 按过期时间排序的小根堆 实现了heap.Interface
+type expireHeap []*expireItem
+
+func (h expireHeap) Len() int           { return len(h) }
+func (h expireHeap) Less(i, j int) bool { return h[i].expire.Before(h[j].expire) }
+func (h expireHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expireHeap) Push(x interface{}) {
+	item := x.(*expireItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *expireHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
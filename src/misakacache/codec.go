@@ -0,0 +1,68 @@
+package misakacache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec 接口 负责缓存值和[]byte之间的转换 TypedGroup通过它编码写入的值和解码读取到的值
+// Decode不能修改或者持有data data就是缓存中保存的数据
+type Codec[T any] interface {
+	Encode(value T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec 使用encoding/json的Codec
+type JSONCodec[T any] struct{}
+
+// Encode JSON编码
+func (JSONCodec[T]) Encode(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Decode JSON解码
+func (JSONCodec[T]) Decode(data []byte) (value T, err error) {
+	err = json.Unmarshal(data, &value)
+	return
+}
+
+// GobCodec 使用encoding/gob的Codec 接口类型的值需要事先gob.Register
+type GobCodec[T any] struct{}
+
+// Encode gob编码
+func (GobCodec[T]) Encode(value T) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode gob解码
+func (GobCodec[T]) Decode(data []byte) (value T, err error) {
+	err = gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return
+}
+
+// ProtoCodec 使用protobuf的Codec New用于在解码时创建一个空的消息 例如func() *pb.Response { return &pb.Response{} }
+type ProtoCodec[T proto.Message] struct {
+	New func() T
+}
+
+// Encode protobuf编码
+func (codec ProtoCodec[T]) Encode(value T) ([]byte, error) {
+	return proto.Marshal(value)
+}
+
+// Decode protobuf解码
+func (codec ProtoCodec[T]) Decode(data []byte) (T, error) {
+	value := codec.New()
+	if err := proto.Unmarshal(data, value); err != nil {
+		var zero T
+		return zero, err
+	}
+	return value, nil
+}
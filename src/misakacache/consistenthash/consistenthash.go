@@ -55,3 +55,25 @@ func (m *Map) GetRealNodeByKey(key string) (result string) {
 
 	return m.hashmap[m.keys[index%len(m.keys)]] // 去映射里查找真实节点
 }
+
+// GetRealNodesByKey 根据key沿着环顺时针获得最多n个不同的真实节点 第一个就是GetRealNodeByKey的结果
+func (m *Map) GetRealNodesByKey(key string, n int) (result []string) {
+	if len(m.keys) == 0 || n <= 0 {
+		return nil
+	}
+
+	keyHash := int(m.hash([]byte(key)))
+	index := sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= keyHash
+	})
+
+	seen := make(map[string]struct{}, n)
+	for i := 0; i < len(m.keys) && len(result) < n; i++ { // 跳过属于已经选中的真实节点的虚拟节点
+		node := m.hashmap[m.keys[(index+i)%len(m.keys)]]
+		if _, ok := seen[node]; !ok {
+			seen[node] = struct{}{}
+			result = append(result, node)
+		}
+	}
+	return
+}
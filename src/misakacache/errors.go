@@ -0,0 +1,105 @@
+package misakacache
+
+import (
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrBadRequest 请求本身有问题 例如key为空或者Group不存在 重试也不会成功
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnavailable Getter在数据源暂时不可用时可以返回该错误（可以用fmt.Errorf的%w包装）
+	// 远程节点收到该错误时会转而从本地加载
+	ErrUnavailable = errors.New("unavailable")
+
+	errKeyRequired = fmt.Errorf("key is required: %w", ErrBadRequest)
+)
+
+// PeerError 远程节点返回的错误 Status是远程节点给出的错误类型
+// 可以用errors.Is判断ErrNotFound、ErrBadRequest、ErrUnavailable和context.DeadlineExceeded
+type PeerError struct {
+	Status  pb.Status
+	Message string
+}
+
+// Error 实现error接口
+func (e *PeerError) Error() string {
+	return fmt.Sprintf("peer returned %v: %s", e.Status, e.Message)
+}
+
+// Unwrap 返回Status对应的错误
+func (e *PeerError) Unwrap() error {
+	switch e.Status {
+	case pb.Status_STATUS_NOT_FOUND:
+		return ErrNotFound
+	case pb.Status_STATUS_BAD_REQUEST:
+		return ErrBadRequest
+	case pb.Status_STATUS_UNAVAILABLE:
+		return ErrUnavailable
+	case pb.Status_STATUS_DEADLINE_EXCEEDED:
+		return context.DeadlineExceeded
+	default:
+		return nil
+	}
+}
+
+// statusOf 把Group返回的错误转换成节点之间传递的错误类型 远程节点返回的错误原样传递
+func statusOf(err error) pb.Status {
+	var peerErr *PeerError
+	switch {
+	case err == nil:
+		return pb.Status_STATUS_OK
+	case errors.As(err, &peerErr):
+		return peerErr.Status
+	case errors.Is(err, ErrNotFound):
+		return pb.Status_STATUS_NOT_FOUND
+	case errors.Is(err, ErrBadRequest):
+		return pb.Status_STATUS_BAD_REQUEST
+	case errors.Is(err, ErrUnavailable), errors.Is(err, context.Canceled):
+		return pb.Status_STATUS_UNAVAILABLE
+	case errors.Is(err, context.DeadlineExceeded):
+		return pb.Status_STATUS_DEADLINE_EXCEEDED
+	default:
+		return pb.Status_STATUS_INTERNAL
+	}
+}
+
+// httpStatusCodes 错误类型和HTTP状态码的对应关系
+var httpStatusCodes = map[pb.Status]int{
+	pb.Status_STATUS_OK:                http.StatusOK,
+	pb.Status_STATUS_NOT_FOUND:         http.StatusNotFound,
+	pb.Status_STATUS_BAD_REQUEST:       http.StatusBadRequest,
+	pb.Status_STATUS_UNAVAILABLE:       http.StatusServiceUnavailable,
+	pb.Status_STATUS_DEADLINE_EXCEEDED: http.StatusGatewayTimeout,
+	pb.Status_STATUS_INTERNAL:          http.StatusInternalServerError,
+}
+
+// httpStatusCode 返回错误类型对应的HTTP状态码
+func httpStatusCode(status pb.Status) int {
+	if code, ok := httpStatusCodes[status]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// statusFromHTTP 根据HTTP状态码推断错误类型 用于响应体不是Error的情况 例如请求被代理拦截
+func statusFromHTTP(code int) pb.Status {
+	for status, statusCode := range httpStatusCodes {
+		if statusCode == code {
+			return status
+		}
+	}
+	if code == http.StatusBadGateway {
+		return pb.Status_STATUS_UNAVAILABLE
+	}
+	return pb.Status_STATUS_INTERNAL
+}
+
+// shouldFallback 从远程节点加载失败后 是否应该转而从本地加载
+// key不存在和请求本身有问题时 从本地加载也不会得到不同的结果
+func shouldFallback(err error) bool {
+	return !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrBadRequest)
+}
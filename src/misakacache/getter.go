@@ -0,0 +1,77 @@
+package misakacache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Getter 接口 规定了一个Get方法 该方法用于规定缓存未命中时从哪里获得新的缓存
+// ctx来自调用Group.Get的调用方 调用方取消或者超时后 Get应当尽快返回
+type Getter interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// GetterFunc 函数类型 专门用来实现Getter接口的函数类型 不关心ctx的旧式Getter可以直接使用该类型
+type GetterFunc func(key string) ([]byte, error)
+
+// Get GetterFunc类下的 从Getter接口的Get函数实现而来的函数 忽略ctx
+func (function GetterFunc) Get(_ context.Context, key string) ([]byte, error) {
+	return function(key)
+}
+
+// ContextGetterFunc 函数类型 和GetterFunc相同 但是可以拿到调用方的ctx
+type ContextGetterFunc func(ctx context.Context, key string) ([]byte, error)
+
+// Get ContextGetterFunc类下的 从Getter接口的Get函数实现而来的函数
+func (function ContextGetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
+	return function(ctx, key)
+}
+
+// TTLGetter 接口 和Getter类似 但是可以同时返回该缓存的存活时间
+// 如果Group的getter同时实现了该接口 则优先使用GetWithTTL 返回的ttl为0时使用Group的默认存活时间
+type TTLGetter interface {
+	GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error)
+}
+
+// TTLGetterFunc 函数类型 专门用来实现TTLGetter接口的函数类型 同时也实现了Getter接口
+type TTLGetterFunc func(ctx context.Context, key string) ([]byte, time.Duration, error)
+
+// GetWithTTL TTLGetterFunc类下的 从TTLGetter接口的GetWithTTL函数实现而来的函数
+func (function TTLGetterFunc) GetWithTTL(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	return function(ctx, key)
+}
+
+// Get TTLGetterFunc类下的 从Getter接口的Get函数实现而来的函数 忽略返回的存活时间
+func (function TTLGetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
+	bytes, _, err := function(ctx, key)
+	return bytes, err
+}
+
+// BatchGetter 接口 一次从数据源加载多个key 返回的map中不存在的key视为ErrNotFound
+// 如果Group的getter同时实现了该接口 则GetMany中本地未命中的key会通过一次GetBatch加载
+// 通过WithBatchWindow开启后 短时间内并发到达的Get未命中也会合并成一次GetBatch
+type BatchGetter interface {
+	GetBatch(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// BatchGetterFunc 函数类型 专门用来实现BatchGetter接口的函数类型 同时也实现了Getter接口
+type BatchGetterFunc func(ctx context.Context, keys []string) (map[string][]byte, error)
+
+// GetBatch BatchGetterFunc类下的 从BatchGetter接口的GetBatch函数实现而来的函数
+func (function BatchGetterFunc) GetBatch(ctx context.Context, keys []string) (map[string][]byte, error) {
+	return function(ctx, keys)
+}
+
+// Get BatchGetterFunc类下的 从Getter接口的Get函数实现而来的函数 相当于只有一个key的GetBatch
+func (function BatchGetterFunc) Get(ctx context.Context, key string) ([]byte, error) {
+	values, err := function(ctx, []string{key})
+	if err != nil {
+		return nil, err
+	}
+	bytes, ok := values[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return bytes, nil
+}
@@ -0,0 +1,311 @@
+package misakacache
+
+import (
+	"MisakaCache/src/misakacache/consistenthash"
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCPool 和HTTPPool作用相同的节点池 节点之间通过gRPC通信
+// 它既是PeerPicker 可以直接传给Group.RegisterPeers 也是GroupCache服务的服务端实现
+// 每个远程节点对应一个长期复用的grpc.ClientConn 所有请求在这条连接上多路复用
+type GRPCPool struct {
+	pb.UnimplementedGroupCacheServer
+
+	selfAddr    string // 记录自身的地址 格式为host:port
+	mu          sync.Mutex
+	peers       *consistenthash.Map
+	clients     map[string]*grpcClient // 每个远程节点一个客户端
+	version     atomic.Uint64          // 远程节点的版本号 每次SetNewPeer成功后增加
+	registry    *Registry              // 收到请求时从这里查找Group
+	dialOptions []grpc.DialOption      // 创建连接时使用的选项
+}
+
+// NewGRPCPool GRPCPool的构造方法 使用默认的Registry 没有指定dialOptions时使用不加密的连接
+func NewGRPCPool(selfAddr string, dialOptions ...grpc.DialOption) *GRPCPool {
+	if len(dialOptions) == 0 {
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	return &GRPCPool{
+		selfAddr:    selfAddr,
+		registry:    DefaultRegistry,
+		dialOptions: dialOptions,
+	}
+}
+
+// NewGRPCPool 创建一个只为该Registry中的Group提供服务的GRPCPool
+func (r *Registry) NewGRPCPool(selfAddr string, dialOptions ...grpc.DialOption) *GRPCPool {
+	pool := NewGRPCPool(selfAddr, dialOptions...)
+	pool.registry = r
+	return pool
+}
+
+// Log 记录信息
+func (p *GRPCPool) Log(format string, v ...interface{}) {
+	log.Printf("[Server %s] %s", p.selfAddr, fmt.Sprintf(format, v...))
+}
+
+// NewServer 创建一个已经注册了GroupCache服务的grpc.Server
+func (p *GRPCPool) NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	pb.RegisterGroupCacheServer(server, p)
+	return server
+}
+
+// SetNewPeer 更新远程节点 已经存在的连接会被复用 不再使用的连接会被关闭
+func (p *GRPCPool) SetNewPeer(peers ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clients := make(map[string]*grpcClient, len(peers))
+	for _, peer := range peers {
+		if _, ok := clients[peer]; ok { // 重复的地址只连接一次
+			continue
+		}
+		if client, ok := p.clients[peer]; ok {
+			clients[peer] = client
+			continue
+		}
+		if peer == p.selfAddr { // 不需要连接自身
+			continue
+		}
+		conn, err := grpc.NewClient(peer, p.dialOptions...)
+		if err != nil {
+			for addr, client := range clients { // 关闭这次新建的连接 已有的连接仍然在使用
+				if _, ok := p.clients[addr]; !ok {
+					client.conn.Close()
+				}
+			}
+			return fmt.Errorf("dial %s: %w", peer, err)
+		}
+		clients[peer] = &grpcClient{conn: conn, client: pb.NewGroupCacheClient(conn)}
+	}
+	for peer, client := range p.clients {
+		if _, ok := clients[peer]; !ok {
+			client.conn.Close()
+		}
+	}
+
+	p.peers = consistenthash.NewMap(nil, defaultReplicas)
+	p.peers.AddRealNode(peers...)
+	p.clients = clients
+	p.version.Add(1)
+	return nil
+}
+
+// Peers 返回当前所有的远程节点
+func (p *GRPCPool) Peers() []PeerCacheValueGetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]PeerCacheValueGetter, 0, len(p.clients))
+	for _, client := range p.clients {
+		peers = append(peers, client)
+	}
+	return peers
+}
+
+// PeersVersion 返回远程节点的版本号
+func (p *GRPCPool) PeersVersion() uint64 {
+	return p.version.Load()
+}
+
+// PickPeer 根据一致性哈希挑选合适的远程节点
+func (p *GRPCPool) PickPeer(key string) (PeerCacheValueGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	peer := p.peers.GetRealNodeByKey(key)
+	if peer == "" || peer == p.selfAddr { // 排除自身节点
+		return nil, false
+	}
+	client, ok := p.clients[peer]
+	if !ok { // 不能把nil的*grpcClient放进接口返回
+		return nil, false
+	}
+	return client, true
+}
+
+// PickPeers 按一致性哈希环上的顺序挑选最多n个远程节点 遇到自身节点时停止
+func (p *GRPCPool) PickPeers(key string, n int) (result []PeerCacheValueGetter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil
+	}
+	for _, peer := range p.peers.GetRealNodesByKey(key, n) {
+		client, ok := p.clients[peer]
+		if peer == p.selfAddr || !ok {
+			break
+		}
+		result = append(result, client)
+	}
+	return
+}
+
+// Close 关闭所有远程节点的连接
+func (p *GRPCPool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	for _, client := range p.clients {
+		err = errors.Join(err, client.conn.Close())
+	}
+	p.clients = nil
+	p.peers = nil
+	return err
+}
+
+// Get 实现GroupCacheServer 响应读取缓存的请求
+func (p *GRPCPool) Get(ctx context.Context, in *pb.Request) (*pb.Response, error) {
+	group, err := p.group(in.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	view, err := group.Get(ctx, in.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Response{Value: view.GetByteCopy()}, nil
+}
+
+// Set 实现GroupCacheServer 本节点就是key的所属节点 直接写入本地缓存
+func (p *GRPCPool) Set(ctx context.Context, in *pb.SetRequest) (*pb.Response, error) {
+	group, err := p.group(in.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	group.setLocally(in.GetKey(), in.GetValue(), time.Duration(in.GetTtlMs())*time.Millisecond)
+	return &pb.Response{}, nil
+}
+
+// Remove 实现GroupCacheServer 删除本地缓存或者把它标记为过期
+func (p *GRPCPool) Remove(ctx context.Context, in *pb.RemoveRequest) (*pb.Response, error) {
+	group, err := p.group(in.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	group.removeLocally(in.GetKey(), in.GetInvalidate())
+	return &pb.Response{}, nil
+}
+
+// GetMany 实现GroupCacheServer 响应批量读取缓存的请求 响应中只包含存在的key
+func (p *GRPCPool) GetMany(ctx context.Context, in *pb.BatchRequest) (*pb.BatchResponse, error) {
+	group, err := p.group(in.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	views, err := group.GetMany(ctx, in.GetKeys())
+	if err != nil { // 部分key加载失败 让请求方自己重新加载
+		return nil, toStatus(err)
+	}
+	out := &pb.BatchResponse{Values: make([]*pb.KeyValue, 0, len(views))}
+	for _, key := range in.GetKeys() { // 按请求的顺序返回
+		if view, ok := views[key]; ok {
+			out.Values = append(out.Values, &pb.KeyValue{Key: key, Value: view.GetByteCopy()})
+			delete(views, key) // 重复的key只返回一次
+		}
+	}
+	return out, nil
+}
+
+// group 查找请求的Group 不存在时返回InvalidArgument
+func (p *GRPCPool) group(name string) (*Group, error) {
+	group := p.registry.GetGroup(name)
+	if group == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "no such group: %s", name)
+	}
+	return group, nil
+}
+
+// grpcCodes Status到gRPC状态码的映射
+var grpcCodes = map[pb.Status]codes.Code{
+	pb.Status_STATUS_OK:                codes.OK,
+	pb.Status_STATUS_NOT_FOUND:         codes.NotFound,
+	pb.Status_STATUS_BAD_REQUEST:       codes.InvalidArgument,
+	pb.Status_STATUS_UNAVAILABLE:       codes.Unavailable,
+	pb.Status_STATUS_DEADLINE_EXCEEDED: codes.DeadlineExceeded,
+	pb.Status_STATUS_INTERNAL:          codes.Internal,
+}
+
+// toStatus 把Group返回的错误转换成gRPC的状态码
+func toStatus(err error) error {
+	return status.Error(grpcCodes[statusOf(err)], err.Error())
+}
+
+// fromStatus 把远程节点返回的gRPC状态码转换回*PeerError
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	s := status.Convert(err)
+	for st, code := range grpcCodes {
+		if code == s.Code() && st != pb.Status_STATUS_OK {
+			return &PeerError{Status: st, Message: s.Message()}
+		}
+	}
+	if s.Code() == codes.Canceled { // 请求被取消和节点不可用一样 都可以换一个地方加载
+		return &PeerError{Status: pb.Status_STATUS_UNAVAILABLE, Message: s.Message()}
+	}
+	return &PeerError{Status: pb.Status_STATUS_INTERNAL, Message: s.Message()}
+}
+
+var (
+	_ PeerPicker           = (*GRPCPool)(nil)
+	_ PeerListPicker       = (*GRPCPool)(nil)
+	_ PeerLister           = (*GRPCPool)(nil)
+	_ pb.GroupCacheServer  = (*GRPCPool)(nil)
+	_ PeerCacheValueGetter = (*grpcClient)(nil)
+	_ PeerCacheValueWriter = (*grpcClient)(nil)
+	_ PeerBatchGetter      = (*grpcClient)(nil)
+)
+
+// grpcClient gRPC客户端 一个远程节点对应一个
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	client pb.GroupCacheClient
+}
+
+// GetCacheFromPeer 实现PeerCacheValueGetter接口 从远程节点获得缓存
+func (c *grpcClient) GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	resp, err := c.client.Get(ctx, in)
+	if err != nil {
+		return fromStatus(err)
+	}
+	out.Value = resp.GetValue()
+	return nil
+}
+
+// GetManyFromPeer 实现PeerBatchGetter接口 一次请求从远程节点获取多个key的缓存
+func (c *grpcClient) GetManyFromPeer(ctx context.Context, in *pb.BatchRequest, out *pb.BatchResponse) error {
+	resp, err := c.client.GetMany(ctx, in)
+	if err != nil {
+		return fromStatus(err)
+	}
+	out.Values = resp.GetValues()
+	return nil
+}
+
+// SetCacheToPeer 实现PeerCacheValueWriter接口 在远程节点上写入缓存
+func (c *grpcClient) SetCacheToPeer(ctx context.Context, in *pb.SetRequest) error {
+	_, err := c.client.Set(ctx, in)
+	return fromStatus(err)
+}
+
+// RemoveCacheFromPeer 实现PeerCacheValueWriter接口 在远程节点上删除缓存或者把它标记为过期
+func (c *grpcClient) RemoveCacheFromPeer(ctx context.Context, in *pb.RemoveRequest) error {
+	_, err := c.client.Remove(ctx, in)
+	return fromStatus(err)
+}
@@ -3,19 +3,31 @@ package misakacache
 import (
 	"MisakaCache/src/misakacache/consistenthash"
 	pb "MisakaCache/src/misakacache/misakacachepb"
+	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/protobuf/proto"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
-	defaultBasePath = "/_geecache/" // 默认资源地址
-	defaultReplicas = 50            // 默认真实节点和虚拟节点倍数
+	defaultBasePath            = "/_geecache/" // 默认资源地址
+	defaultReplicas            = 50            // 默认真实节点和虚拟节点倍数
+	defaultMaxIdleConnsPerPeer = 16            // 默认每个远程节点保留的空闲连接数
+
+	protobufContentType = "application/octet-stream" // 请求体和响应体都是protobuf编码
 )
 
 // HTTPPool 一个缓存对应一个HTTP池 记录自身的地址和URL
@@ -25,17 +37,133 @@ type HTTPPool struct {
 	mu          sync.Mutex
 	peers       *consistenthash.Map
 	httpGetters map[string]*httpClient // 集成一个HTTP客户端
+	version     atomic.Uint64          // 远程节点的版本号 每次SetNewPeer后增加
+	registry    *Registry              // 收到请求时从这里查找Group
+
+	client              *http.Client      // 所有远程节点共用的HTTP客户端 连接池按远程节点区分
+	transport           http.RoundTripper // 指定的Transport 为nil时根据下面的配置创建
+	requestTimeout      time.Duration     // 单次请求的超时时间 为0时不限制
+	maxIdleConnsPerPeer int               // 每个远程节点保留的空闲连接数
+	h2c                 bool              // 是否使用不加密的HTTP/2
+
+	replicas int                     // 真实节点和虚拟节点倍数
+	hashFunc consistenthash.HashFunc // 一致性哈希使用的哈希函数 为nil时使用CRC32
+}
+
+// HTTPPoolOption HTTPPool的可选配置项 在NewHTTPPool中按顺序生效
+type HTTPPoolOption func(pool *HTTPPool)
+
+// WithTransport 指定请求远程节点时使用的http.RoundTripper 指定后WithMaxIdleConnsPerPeer和WithHTTP2Cleartext对客户端不再生效
+func WithTransport(transport http.RoundTripper) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		pool.transport = transport
+	}
+}
+
+// WithRequestTimeout 指定单次请求远程节点的超时时间 包括读取响应体 默认为0 即不限制
+// 远程节点卡住时 请求会在超时后失败 Group会转而从本地加载
+func WithRequestTimeout(timeout time.Duration) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		pool.requestTimeout = timeout
+	}
+}
+
+// WithMaxIdleConnsPerPeer 指定每个远程节点保留的空闲连接数 默认为16
+// 开启WithHTTP2Cleartext时不生效 h2c的所有请求在每个远程节点的一条连接上多路复用 不需要保留更多的空闲连接
+func WithMaxIdleConnsPerPeer(n int) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		pool.maxIdleConnsPerPeer = n
+	}
+}
+
+// WithHTTP2Cleartext 节点之间使用不加密的HTTP/2（h2c） 所有请求在每个远程节点的一条连接上多路复用
+// 服务端需要使用Handler()返回的http.Handler 所有节点必须同时开启
+func WithHTTP2Cleartext() HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		pool.h2c = true
+	}
+}
+
+// WithBasePath 指定节点之间通信使用的路径前缀 默认为/_geecache/ 同一个集群的所有节点必须相同
+// 多个集群共用一个入口时 可以用不同的路径前缀区分
+func WithBasePath(basePath string) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		if !strings.HasPrefix(basePath, "/") {
+			basePath = "/" + basePath
+		}
+		if !strings.HasSuffix(basePath, "/") {
+			basePath += "/"
+		}
+		pool.basePath = basePath
+	}
+}
+
+// WithReplicas 指定一致性哈希中每个真实节点对应的虚拟节点数量 默认为50 越多key的分布越均匀 小于等于0时使用默认值
+func WithReplicas(replicas int) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		if replicas <= 0 { // 没有虚拟节点时选不出任何远程节点 每个节点都会从本地加载所有key
+			replicas = defaultReplicas
+		}
+		pool.replicas = replicas
+	}
+}
+
+// WithHashFunc 指定一致性哈希使用的哈希函数 默认为CRC32 同一个集群的所有节点必须相同
+func WithHashFunc(hashFunc consistenthash.HashFunc) HTTPPoolOption {
+	return func(pool *HTTPPool) {
+		pool.hashFunc = hashFunc
+	}
 }
 
-// NewHTTPPool HTTPPool的构造方法
-func NewHTTPPool(selfAddr string) (result *HTTPPool) {
+// NewHTTPPool HTTPPool的构造方法 使用默认的Registry
+func NewHTTPPool(selfAddr string, opts ...HTTPPoolOption) (result *HTTPPool) {
 	result = &HTTPPool{
-		selfAddr: selfAddr,
-		basePath: defaultBasePath,
+		selfAddr:            selfAddr,
+		basePath:            defaultBasePath,
+		registry:            DefaultRegistry,
+		maxIdleConnsPerPeer: defaultMaxIdleConnsPerPeer,
+		replicas:            defaultReplicas,
 	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	result.client = &http.Client{Transport: result.newTransport(), Timeout: result.requestTimeout}
 	return
 }
 
+// newTransport 根据配置创建请求远程节点时使用的Transport
+func (pool *HTTPPool) newTransport() http.RoundTripper {
+	if pool.transport != nil {
+		return pool.transport
+	}
+	if pool.h2c {
+		return &http2.Transport{
+			AllowHTTP: true, // 允许http://地址 并且用普通的TCP连接代替TLS连接
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = pool.maxIdleConnsPerPeer
+	transport.MaxIdleConns = 0 // 不限制总数 由每个远程节点的上限控制
+	return transport
+}
+
+// Transport 返回请求远程节点时实际使用的http.RoundTripper
+func (pool *HTTPPool) Transport() http.RoundTripper {
+	return pool.client.Transport
+}
+
+// Handler 返回服务端使用的http.Handler 开启了WithHTTP2Cleartext时支持h2c 否则就是HTTPPool本身
+func (pool *HTTPPool) Handler() http.Handler {
+	if pool.h2c {
+		return h2c.NewHandler(pool, &http2.Server{})
+	}
+	return pool
+}
+
 // Log 记录信息 参数v可传多个值 这些值会按format来进行格式化 再进入log
 func (pool *HTTPPool) Log(format string, v ...interface{}) {
 	log.Printf("[Server %s] %s", pool.selfAddr, fmt.Sprintf(format, v...))
@@ -49,37 +177,116 @@ func (pool *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	pool.Log("%s %s", r.Method, r.URL.Path) // log记录该次请求的信息
 
 	parts := strings.SplitN(r.URL.Path[len(pool.basePath):], "/", 2)
-	if len(parts) != 2 { // 检查请求是否有效
-		http.Error(w, "bad request", http.StatusBadRequest) // 400
+	batch := r.Method == http.MethodPost && len(parts) == 1 // 批量请求的路径中只有group 没有key
+	if len(parts) != 2 && !batch {                          // 检查请求是否有效
+		writeError(w, pb.Status_STATUS_BAD_REQUEST, "bad request") // 400
 		return
 	}
 
 	groupName := parts[0]
+
+	group := pool.registry.GetGroup(groupName)
+	if group == nil { // 请求的缓存不存在 404留给ErrNotFound 这里返回400
+		writeError(w, pb.Status_STATUS_BAD_REQUEST, "no such group: "+groupName) // 400
+		return
+	}
+	if batch {
+		pool.serveGetMany(w, r, group)
+		return
+	}
+
 	key := parts[1]
+	switch r.Method {
+	case http.MethodPut:
+		pool.serveSet(w, r, group, key)
+	case http.MethodDelete:
+		pool.serveRemove(w, r, group, key)
+	default:
+		pool.serveGet(w, r, group, key)
+	}
+}
 
-	group := GetGroup(groupName)
-	if group == nil { // 请求的缓存不存在
-		http.Error(w, "no such group:"+groupName, http.StatusNotFound) // 404
+// serveGet 响应读取缓存的请求
+func (pool *HTTPPool) serveGet(w http.ResponseWriter, r *http.Request, group *Group, key string) {
+	view, err := group.Get(r.Context(), key) // 请求方断开连接时不再等待
+	if err != nil {                          // 缓存请求失败 按错误类型返回对应的状态码 例如key不存在时返回404 方便请求方做负缓存
+		writeError(w, statusOf(err), err.Error())
+		return
+	}
+	writeMessage(w, &pb.Response{Value: view.GetByteCopy()})
+}
+
+// serveGetMany 响应批量读取缓存的请求 请求体是BatchRequest 响应体是BatchResponse 其中只包含存在的key
+func (pool *HTTPPool) serveGetMany(w http.ResponseWriter, r *http.Request, group *Group) {
+	in := &pb.BatchRequest{}
+	if !readMessage(w, r, in) {
 		return
 	}
 
-	view, err := group.GetFromCache(key) // fixme
-	if err != nil {                      // 缓存请求失败
-		http.Error(w, err.Error(), http.StatusInternalServerError) // 500
+	views, err := group.GetMany(r.Context(), in.GetKeys())
+	if err != nil { // 部分key加载失败 让请求方自己重新加载
+		writeError(w, statusOf(err), err.Error())
 		return
 	}
+	out := &pb.BatchResponse{Values: make([]*pb.KeyValue, 0, len(views))}
+	for _, key := range in.GetKeys() { // 按请求的顺序返回
+		if view, ok := views[key]; ok {
+			out.Values = append(out.Values, &pb.KeyValue{Key: key, Value: view.GetByteCopy()})
+			delete(views, key) // 重复的key只返回一次
+		}
+	}
 
-	body, err := proto.Marshal(&pb.Response{Value: view.GetByteCopy()})
+	writeMessage(w, out)
+}
+
+// serveSet 响应写入缓存的请求 请求体是SetRequest 本节点就是key的所属节点 所以直接写入本地缓存
+func (pool *HTTPPool) serveSet(w http.ResponseWriter, r *http.Request, group *Group, key string) {
+	in := &pb.SetRequest{}
+	if !readMessage(w, r, in) {
+		return
+	}
+	group.setLocally(key, in.GetValue(), time.Duration(in.GetTtlMs())*time.Millisecond)
+	w.WriteHeader(http.StatusNoContent) // 204
+}
+
+// serveRemove 响应删除缓存的请求 查询参数invalidate=true时只标记为过期
+func (pool *HTTPPool) serveRemove(w http.ResponseWriter, r *http.Request, group *Group, key string) {
+	invalidate, _ := strconv.ParseBool(r.URL.Query().Get("invalidate"))
+	group.removeLocally(key, invalidate)
+	w.WriteHeader(http.StatusNoContent) // 204
+}
+
+// readMessage 读取并解码请求体 失败时写入400并返回false
+func readMessage(w http.ResponseWriter, r *http.Request, in proto.Message) bool {
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, pb.Status_STATUS_BAD_REQUEST, "reading request body error: "+err.Error()) // 400
+		return false
+	}
+	if err = proto.Unmarshal(body, in); err != nil {
+		writeError(w, pb.Status_STATUS_BAD_REQUEST, "decoding request body: "+err.Error()) // 400
+		return false
 	}
+	return true
+}
 
-	w.Header().Set("Content-Type", "application/octet-stream")
-	_, err = w.Write(body)
-	if err != nil { // 写入响应失败
-		http.Error(w, "write into responseWriter error: "+err.Error(), http.StatusInternalServerError) // 500
+// writeMessage 编码并写入响应体
+func writeMessage(w http.ResponseWriter, out proto.Message) {
+	body, err := proto.Marshal(out)
+	if err != nil {
+		writeError(w, pb.Status_STATUS_INTERNAL, err.Error()) // 500
 		return
 	}
+	w.Header().Set("Content-Type", protobufContentType)
+	w.Write(body) // 写入失败说明请求方已经断开 无法再通知它
+}
+
+// writeError 写入错误响应 状态码和Error.Status对应 响应体是编码后的Error 请求方可以据此还原错误类型
+func writeError(w http.ResponseWriter, status pb.Status, message string) {
+	body, _ := proto.Marshal(&pb.Error{Status: status, Message: message})
+	w.Header().Set("Content-Type", protobufContentType)
+	w.WriteHeader(httpStatusCode(status))
+	w.Write(body)
 }
 
 // SetNewPeer 在本节点初始化远程节点信息
@@ -87,12 +294,36 @@ func (p *HTTPPool) SetNewPeer(peers ...string) {
 	p.mu.Lock() // attention 加锁必要性?
 	defer p.mu.Unlock()
 
-	p.peers = consistenthash.NewMap(nil, defaultReplicas)
+	p.peers = consistenthash.NewMap(p.hashFunc, p.replicas)
 	p.peers.AddRealNode(peers...)
-	p.httpGetters = make(map[string]*httpClient, len(peers))
+	httpGetters := make(map[string]*httpClient, len(peers))
 	for _, peer := range peers {
-		p.httpGetters[peer] = &httpClient{baseURL: peer + p.basePath} // attention 这里的路径构建可能会有问题
+		if getter, ok := p.httpGetters[peer]; ok { // 复用已有的客户端 Group中该节点的熔断器状态得以保留
+			httpGetters[peer] = getter
+			continue
+		}
+		httpGetters[peer] = &httpClient{baseURL: peer + p.basePath, client: p.client} // attention 这里的路径构建可能会有问题
 	}
+	p.httpGetters = httpGetters
+	p.version.Add(1)
+}
+
+// Peers 返回当前所有的远程节点 不包括自身节点
+func (p *HTTPPool) Peers() []PeerCacheValueGetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]PeerCacheValueGetter, 0, len(p.httpGetters))
+	for addr, getter := range p.httpGetters {
+		if addr != p.selfAddr {
+			peers = append(peers, getter)
+		}
+	}
+	return peers
+}
+
+// PeersVersion 返回远程节点的版本号
+func (p *HTTPPool) PeersVersion() uint64 {
+	return p.version.Load()
 }
 
 // PickPeer 根据一致性哈希挑选合适的远程节点
@@ -107,37 +338,112 @@ func (p *HTTPPool) PickPeer(key string) (PeerCacheValueGetter, bool) {
 	return nil, false
 }
 
-var _ PeerPicker = (*HTTPPool)(nil)
+// PickPeers 按一致性哈希环上的顺序挑选最多n个远程节点 遇到自身节点时停止
+func (p *HTTPPool) PickPeers(key string, n int) (result []PeerCacheValueGetter) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil
+	}
+	for _, peer := range p.peers.GetRealNodesByKey(key, n) {
+		if peer == p.selfAddr {
+			break
+		}
+		result = append(result, p.httpGetters[peer])
+	}
+	return
+}
+
+var (
+	_ PeerPicker     = (*HTTPPool)(nil)
+	_ PeerListPicker = (*HTTPPool)(nil)
+	_ PeerLister     = (*HTTPPool)(nil)
+)
 
 // httpClient HTTP客户端 向远程节点发送请求 一个远程节点对应一个HTTP客户端
 type httpClient struct {
 	baseURL string
+	client  *http.Client // HTTPPool中所有远程节点共用的客户端
 }
 
 // GetCacheFromPeer 实现PeerCacheValueGetter接口 从远程节点获得缓存
-func (h *httpClient) GetCacheFromPeer(in *pb.Request, out *pb.Response) error {
-	URL := fmt.Sprintf("%v%v/%v", h.baseURL, url.QueryEscape(in.GetGroup()), url.QueryEscape(in.GetKey()))
+func (h *httpClient) GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error {
+	return h.do(ctx, http.MethodGet, h.keyURL(in.GetGroup(), in.GetKey()), nil, out)
+}
+
+// GetManyFromPeer 实现PeerBatchGetter接口 一次请求从远程节点获取多个key的缓存
+func (h *httpClient) GetManyFromPeer(ctx context.Context, in *pb.BatchRequest, out *pb.BatchResponse) error {
+	return h.do(ctx, http.MethodPost, h.baseURL+url.QueryEscape(in.GetGroup()), in, out)
+}
+
+// SetCacheToPeer 实现PeerCacheValueWriter接口 在远程节点上写入缓存
+func (h *httpClient) SetCacheToPeer(ctx context.Context, in *pb.SetRequest) error {
+	return h.do(ctx, http.MethodPut, h.keyURL(in.GetGroup(), in.GetKey()), in, nil)
+}
+
+// RemoveCacheFromPeer 实现PeerCacheValueWriter接口 在远程节点上删除缓存或者把它标记为过期
+func (h *httpClient) RemoveCacheFromPeer(ctx context.Context, in *pb.RemoveRequest) error {
+	URL := h.keyURL(in.GetGroup(), in.GetKey())
+	if in.GetInvalidate() {
+		URL += "?invalidate=true"
+	}
+	return h.do(ctx, http.MethodDelete, URL, nil, nil)
+}
+
+// keyURL 构建group和key对应的请求地址
+func (h *httpClient) keyURL(group, key string) string {
+	return fmt.Sprintf("%v%v/%v", h.baseURL, url.QueryEscape(group), url.QueryEscape(key))
+}
 
-	resp, err := http.Get(URL)
+// do 发送请求 in不为nil时作为请求体 out不为nil时把响应体解码到out
+// 远程节点返回错误时 返回带有错误类型的*PeerError
+func (h *httpClient) do(ctx context.Context, method, URL string, in, out proto.Message) error {
+	var body io.Reader
+	if in != nil {
+		data, err := proto.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding request body: %v", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, URL, body)
+	if err != nil {
+		return err
+	}
+	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("server returned error: %v", resp.Status)
+	data, err := io.ReadAll(resp.Body) // 读取响应体 原有的ioutil.ReadAll方法被弃用
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return responseError(resp, data)
 	}
-
-	bytes, err := io.ReadAll(resp.Body) // 读取响应体 原有的ioutil.ReadAll方法被弃用
 	if err != nil {
-		err = fmt.Errorf("reading response body error: %v", err)
+		return fmt.Errorf("reading response body error: %v", err)
 	}
-
-	if err = proto.Unmarshal(bytes, out); err != nil {
-		return fmt.Errorf("decoding response body: %v", err)
+	if out != nil {
+		if err = proto.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decoding response body: %v", err)
+		}
 	}
-
 	return nil
 }
 
-var _ PeerCacheValueGetter = (*httpClient)(nil) // 检查接口是否被完整实现
+// responseError 把错误响应还原成*PeerError 响应体不是Error时（例如被代理拦截）根据状态码推断错误类型
+func responseError(resp *http.Response, body []byte) error {
+	if resp.Header.Get("Content-Type") == protobufContentType {
+		e := &pb.Error{}
+		if err := proto.Unmarshal(body, e); err == nil && e.GetStatus() != pb.Status_STATUS_OK {
+			return &PeerError{Status: e.GetStatus(), Message: e.GetMessage()}
+		}
+	}
+	return &PeerError{Status: statusFromHTTP(resp.StatusCode), Message: strings.TrimSpace(string(body))}
+}
+
+var (
+	_ PeerCacheValueGetter = (*httpClient)(nil) // 检查接口是否被完整实现
+	_ PeerCacheValueWriter = (*httpClient)(nil)
+	_ PeerBatchGetter      = (*httpClient)(nil)
+)
@@ -0,0 +1,196 @@
+package lfu
+
+import (
+	"MisakaCache/src/misakacache/lru"
+	"container/list"
+)
+
+/*
+LFU算法 Least Frequently Used 最少使用算法 每次缓存满时会淘汰访问次数最少的缓存
+
+朴素的LFU需要在淘汰时对访问次数排序 这里采用频率桶的方式把所有操作都降到O(1)：
+所有访问次数相同的缓存放在同一个桶里 桶按访问次数升序串成一条双向链表 每个桶内部又是一条按访问时间排序的双向链表
+一次访问只需要把缓存从当前桶移动到下一个桶（访问次数+1的桶 不存在则新建） 淘汰时直接取第一个桶的队首即可
+
+LFU的另一个问题是以前访问次数很多 但现在已经不再访问的缓存很难被淘汰
+所以这里加入了周期性的频率衰减：每经过一定次数的访问 所有缓存的访问次数减半 这样过时的热点缓存最终也能被淘汰
+*/
+
+const agingFactor = 10 // 访问次数超过缓存数量的agingFactor倍时进行一次衰减
+
+// LFU 采用了LFU策略的缓存类
+type LFU struct {
+	maxMemoryBytes  int64                             // 允许使用的最大内存
+	memoryUsedBytes int64                             // 当前已经使用的内存
+	buckets         *list.List                        // 频率桶链表 按访问次数升序排列 元素类型为*bucket
+	cacheMap        map[string]*list.Element          // 字典 值为桶内链表的元素指针 具体的值在链表里
+	accessCount     int                               // 距离上一次衰减的访问次数
+	OnEntryDeleted  func(key string, value lru.Value) // 当缓存被删除时的回调函数 这种函数类型的默认值就是nil
+}
+
+// NewLFU LFU的构造函数
+func NewLFU(maxMemoryBytes int64, onEntryDeleted func(string, lru.Value)) (cache *LFU) {
+	cache = &LFU{
+		maxMemoryBytes: maxMemoryBytes,
+		buckets:        list.New(),
+		cacheMap:       make(map[string]*list.Element),
+		OnEntryDeleted: onEntryDeleted,
+	}
+	return
+}
+
+// bucket 频率桶 保存所有访问次数为frequency的缓存
+type bucket struct {
+	frequency int
+	entries   *list.List // 桶内的缓存 Front为最近访问的缓存
+}
+
+// entry 桶内链表所存储的类型
+type entry struct {
+	key    string
+	value  lru.Value
+	bucket *list.Element // 该缓存所在的桶 在频率桶链表里的元素指针
+}
+
+// GetValue 在缓存里查找值
+func (cache *LFU) GetValue(key string) (value lru.Value, isOk bool) {
+	if element, isOk := cache.cacheMap[key]; isOk {
+		cache.increment(element)
+		return element.Value.(*entry).value, true
+	}
+	return nil, false
+}
+
+// RemoveOldestCache 淘汰一次缓存 淘汰访问次数最少的桶里最久未访问的缓存
+func (cache *LFU) RemoveOldestCache() {
+	bucketElement := cache.buckets.Front()
+	if bucketElement == nil {
+		return
+	}
+	element := bucketElement.Value.(*bucket).entries.Back()
+	cacheEntry := cache.removeElement(element)
+	if cache.OnEntryDeleted != nil { // 如果回调函数不为空 则调用回调函数
+		cache.OnEntryDeleted(cacheEntry.key, cacheEntry.value)
+	}
+}
+
+// SetValue 添加/修改缓存 修改缓存也视为一次访问
+func (cache *LFU) SetValue(key string, value lru.Value) {
+	if element, isOk := cache.cacheMap[key]; isOk { // 键存在
+		cacheEntry := element.Value.(*entry)
+		cache.memoryUsedBytes += int64(value.GetMemoryUsed()) - int64(cacheEntry.value.GetMemoryUsed())
+		cacheEntry.value = value
+		cache.increment(element)
+	} else { // 键不存在 放进访问次数为1的桶
+		first := cache.buckets.Front()
+		if first == nil || first.Value.(*bucket).frequency != 1 {
+			first = cache.buckets.PushFront(&bucket{frequency: 1, entries: list.New()})
+		}
+		cache.cacheMap[key] = first.Value.(*bucket).entries.PushFront(&entry{key: key, value: value, bucket: first})
+		cache.memoryUsedBytes += int64(len(key)) + int64(value.GetMemoryUsed())
+	}
+	for cache.memoryUsedBytes > cache.maxMemoryBytes && cache.memoryUsedBytes != 0 { // 看已经使用的缓存内存有多大来淘汰旧缓存
+		cache.RemoveOldestCache()
+	}
+}
+
+// RemoveValue 根据键删除一条缓存 返回该键是否存在 主动删除不会触发OnEntryDeleted回调
+func (cache *LFU) RemoveValue(key string) bool {
+	element, isOk := cache.cacheMap[key]
+	if !isOk {
+		return false
+	}
+	cache.removeElement(element)
+	return true
+}
+
+// GetLFUEntryNumber 返回当前已缓存的键值对数量
+func (cache *LFU) GetLFUEntryNumber() int {
+	return len(cache.cacheMap)
+}
+
+// Len 返回当前已缓存的键值对数量
+func (cache *LFU) Len() int {
+	return len(cache.cacheMap)
+}
+
+// BytesUsed 返回当前已经使用的内存
+func (cache *LFU) BytesUsed() int64 {
+	return cache.memoryUsedBytes
+}
+
+// OnEvict 设置缓存被淘汰时的回调函数
+func (cache *LFU) OnEvict(onEntryDeleted func(key string, value lru.Value)) {
+	cache.OnEntryDeleted = onEntryDeleted
+}
+
+// GetFrequency 返回某条缓存当前的访问次数 不存在时返回0 该方法不会记录访问
+func (cache *LFU) GetFrequency(key string) int {
+	if element, isOk := cache.cacheMap[key]; isOk {
+		return element.Value.(*entry).bucket.Value.(*bucket).frequency
+	}
+	return 0
+}
+
+// increment 把缓存移动到访问次数+1的桶里
+func (cache *LFU) increment(element *list.Element) {
+	cacheEntry := element.Value.(*entry)
+	current := cacheEntry.bucket
+	currentBucket := current.Value.(*bucket)
+
+	next := current.Next()
+	if next == nil || next.Value.(*bucket).frequency != currentBucket.frequency+1 {
+		next = cache.buckets.InsertAfter(&bucket{frequency: currentBucket.frequency + 1, entries: list.New()}, current)
+	}
+
+	currentBucket.entries.Remove(element)
+	if currentBucket.entries.Len() == 0 {
+		cache.buckets.Remove(current)
+	}
+	cacheEntry.bucket = next
+	cache.cacheMap[cacheEntry.key] = next.Value.(*bucket).entries.PushFront(cacheEntry)
+
+	cache.accessCount++
+	if cache.accessCount > agingFactor*len(cache.cacheMap) {
+		cache.age()
+	}
+}
+
+// removeElement 从桶和字典中移除一条缓存 并修改已使用的内存
+func (cache *LFU) removeElement(element *list.Element) *entry {
+	cacheEntry := element.Value.(*entry)
+	currentBucket := cacheEntry.bucket.Value.(*bucket)
+	currentBucket.entries.Remove(element)
+	if currentBucket.entries.Len() == 0 {
+		cache.buckets.Remove(cacheEntry.bucket)
+	}
+	delete(cache.cacheMap, cacheEntry.key)
+	cache.memoryUsedBytes -= int64(len(cacheEntry.key)) + int64(cacheEntry.value.GetMemoryUsed())
+	return cacheEntry
+}
+
+// age 频率衰减 所有缓存的访问次数减半（最少为1） 减半后访问次数相同的桶会被合并
+// 减半不改变桶之间的先后顺序 所以只需要和前一个桶比较即可
+func (cache *LFU) age() {
+	cache.accessCount = 0
+	for current := cache.buckets.Front(); current != nil; {
+		next := current.Next()
+		currentBucket := current.Value.(*bucket)
+		currentBucket.frequency /= 2
+		if currentBucket.frequency < 1 {
+			currentBucket.frequency = 1
+		}
+
+		if prev := current.Prev(); prev != nil && prev.Value.(*bucket).frequency == currentBucket.frequency {
+			// 合并进前一个桶 原本访问次数更多的缓存放在前面 淘汰时更晚被淘汰
+			prevBucket := prev.Value.(*bucket)
+			for element := currentBucket.entries.Back(); element != nil; element = element.Prev() {
+				cacheEntry := element.Value.(*entry)
+				cacheEntry.bucket = prev
+				cache.cacheMap[cacheEntry.key] = prevBucket.entries.PushFront(cacheEntry)
+			}
+			cache.buckets.Remove(current)
+		}
+		current = next
+	}
+}
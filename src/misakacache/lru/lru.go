@@ -79,6 +79,16 @@ func (cache *LRU) RemoveOldestCache() {
 	}
 }
 
+// WalkOldest 从下一个将被淘汰的缓存开始 按淘汰顺序依次查看缓存 fn返回false时停止 不会记录访问也不会淘汰
+func (cache *LRU) WalkOldest(fn func(key string, value Value) bool) {
+	for element := cache.queue.Back(); element != nil; element = element.Prev() {
+		cacheEntry := element.Value.(*entry)
+		if !fn(cacheEntry.key, cacheEntry.value) {
+			return
+		}
+	}
+}
+
 // SetValue 添加/修改缓存
 func (cache *LRU) SetValue(key string, value Value) {
 	if element, isOk := cache.cacheMap[key]; isOk { // 键存在
@@ -96,8 +106,36 @@ func (cache *LRU) SetValue(key string, value Value) {
 	}
 }
 
+// RemoveValue 根据键删除一条缓存 返回该键是否存在 主动删除不会触发OnEntryDeleted回调
+func (cache *LRU) RemoveValue(key string) bool {
+	element, isOk := cache.cacheMap[key]
+	if !isOk {
+		return false
+	}
+	cache.queue.Remove(element)
+	cacheEntry := element.Value.(*entry)
+	delete(cache.cacheMap, key)
+	cache.memoryUsedBytes -= int64(len(cacheEntry.key)) + int64(cacheEntry.value.GetMemoryUsed())
+	return true
+}
+
 // GetLRUEntryNumber 返回当前已缓存的键值对数量
 func (cache *LRU) GetLRUEntryNumber() (len int) {
 	len = cache.queue.Len()
 	return
 }
+
+// Len 返回当前已缓存的键值对数量 等同于GetLRUEntryNumber
+func (cache *LRU) Len() int {
+	return cache.queue.Len()
+}
+
+// BytesUsed 返回当前已经使用的内存
+func (cache *LRU) BytesUsed() int64 {
+	return cache.memoryUsedBytes
+}
+
+// OnEvict 设置缓存被淘汰时的回调函数
+func (cache *LRU) OnEvict(onEntryDeleted func(key string, value Value)) {
+	cache.OnEntryDeleted = onEntryDeleted
+}
@@ -0,0 +1,126 @@
+package misakacache
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// metricsContentType Prometheus文本格式的Content-Type
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// groupCounters Group级别的计数器 和StatsEvent一一对应 指标名以misakacache_group_开头 避免和单个缓存的指标重名
+var groupCounters = [...]struct {
+	event StatsEvent
+	help  string
+}{
+	{EventGet, "Total number of get requests."},
+	{EventCacheHit, "Total number of main cache hits."},
+	{EventHotCacheHit, "Total number of hot cache hits."},
+	{EventPeerLoad, "Total number of successful loads from peers."},
+	{EventPeerError, "Total number of failed loads from peers."},
+	{EventLocalLoad, "Total number of successful loads from the getter."},
+	{EventLoadError, "Total number of failed loads from the getter."},
+	{EventLoadDeduped, "Total number of loads merged by singleflight."},
+	{EventPeerRejected, "Total number of peer requests rejected by an open circuit breaker."},
+}
+
+// MetricsHandler 以Prometheus文本格式输出默认Registry中所有Group的统计数据 通常挂载在/metrics
+func MetricsHandler() http.Handler {
+	return DefaultRegistry.MetricsHandler()
+}
+
+// MetricsHandler 以Prometheus文本格式输出该Registry中所有Group的统计数据
+// 不依赖Prometheus的客户端库 只输出counter、gauge和histogram三种类型
+func (r *Registry) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", metricsContentType)
+		writer := bufio.NewWriter(w)
+		writeMetrics(writer, r.Groups())
+		writer.Flush()
+	})
+}
+
+// writeMetrics 输出所有Group的统计数据 同一个指标的所有样本必须写在一起
+func writeMetrics(w *bufio.Writer, groups []*Group) {
+	for _, counter := range groupCounters {
+		name := "misakacache_group_" + counter.event.String() + "_total"
+		writeHeader(w, name, counter.help, "counter")
+		for _, group := range groups {
+			writeSample(w, name, labels("group", group.name), group.stats.counters[counter.event].Load())
+		}
+	}
+
+	cacheStats := make([][2]CacheStats, len(groups)) // 每个Group的缓存主体和热点缓存
+	for i, group := range groups {
+		cacheStats[i] = [2]CacheStats{group.CacheStats(MainCache), group.CacheStats(HotCache)}
+	}
+	cacheMetrics := [...]struct {
+		name, help, kind string
+		value            func(CacheStats) int64
+	}{
+		{"misakacache_cache_bytes", "Bytes used by the cache.", "gauge", func(s CacheStats) int64 { return s.Bytes }},
+		{"misakacache_cache_items", "Number of entries in the cache.", "gauge", func(s CacheStats) int64 { return s.Items }},
+		{"misakacache_cache_gets_total", "Total number of lookups in the cache.", "counter", func(s CacheStats) int64 { return s.Gets }},
+		{"misakacache_cache_hits_total", "Total number of hits in the cache.", "counter", func(s CacheStats) int64 { return s.Hits }},
+		{"misakacache_cache_evictions_total", "Total number of entries evicted by the eviction policy.", "counter", func(s CacheStats) int64 { return s.Evictions }},
+	}
+	for _, metric := range cacheMetrics {
+		writeHeader(w, metric.name, metric.help, metric.kind)
+		for i, group := range groups {
+			writeSample(w, metric.name, labels("group", group.name, "cache", "main"), metric.value(cacheStats[i][0]))
+			if group.hotCache != nil {
+				writeSample(w, metric.name, labels("group", group.name, "cache", "hot"), metric.value(cacheStats[i][1]))
+			}
+		}
+	}
+
+	name := "misakacache_peer_request_duration_seconds"
+	writeHeader(w, name, "Latency of requests to peers.", "histogram")
+	for _, group := range groups {
+		histogram := &group.stats.peerLatency
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += histogram.buckets[i].Load()
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			writeSample(w, name+"_bucket", labels("group", group.name, "le", le), cumulative)
+		}
+		count := histogram.count.Load()
+		writeSample(w, name+"_bucket", labels("group", group.name, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels("group", group.name),
+			strconv.FormatFloat(float64(histogram.sum.Load())/1e9, 'g', -1, 64))
+		writeSample(w, name+"_count", labels("group", group.name), count)
+	}
+}
+
+// writeHeader 输出指标的HELP和TYPE
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample 输出一个样本
+func writeSample(w *bufio.Writer, name, labels string, value int64) {
+	fmt.Fprintf(w, "%s%s %d\n", name, labels, value)
+}
+
+// labelEscaper 转义标签值中的反斜杠、双引号和换行
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels 把成对的标签名和标签值格式化成{name="value",...}
+func labels(pairs ...string) string {
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(pairs[i])
+		builder.WriteString(`="`)
+		builder.WriteString(labelEscaper.Replace(pairs[i+1]))
+		builder.WriteByte('"')
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
@@ -3,92 +3,380 @@ package misakacache
 import (
 	pb "MisakaCache/src/misakacache/misakacachepb"
 	"MisakaCache/src/misakacache/singleflight"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// Getter 接口 规定了一个Get方法 该方法用于规定缓存未命中时从哪里获得新的缓存
-type Getter interface {
-	Get(key string) ([]byte, error)
-}
-
-// GetterFunc 函数类型 专门用来实现Getter接口的函数类型
-type GetterFunc func(key string) ([]byte, error)
-
-// Get GetterFunc类下的 从Getter接口的Get函数实现而来的函数
-func (function GetterFunc) Get(key string) ([]byte, error) {
-	return function(key)
-}
+// ErrNotFound Getter在数据源中找不到key时应当返回该错误（可以用fmt.Errorf的%w包装）
+// 开启负缓存后 该结果会被缓存一段时间 远程节点之间也会以单独的状态码传递该错误
+var ErrNotFound = errors.New("key not found")
 
 // Group 缓存对外交互的核心数据结构
 type Group struct {
-	name      string     // 该缓存的标识
-	getter    Getter     // 缓存未能命中时的回调函数 类型是Getter接口
-	mainCache cache      // 缓存主体 是具有并发保护的LRU缓存
-	peers     PeerPicker // 这是实现了PeerPicker的HTTPPool
+	name      string        // 该缓存的标识
+	getter    Getter        // 缓存未能命中时的回调函数 类型是Getter接口
+	mainCache *shardedCache // 缓存主体 是具有并发保护的分片缓存 淘汰策略默认为LRU
+	hotCache  *shardedCache // 热点缓存 保存从远程节点获取到的热门key 为nil时不开启
+	peers     PeerPicker    // 这是实现了PeerPicker的HTTPPool
 	// attention 为什么要将远程节点集成进HTTPPool 而不是节点本身？ 是否可以优化？
 
 	loader *singleflight.Group // 非本地缓存的并发请求管理
+
+	cacheBytes     int64                                                 // 缓存允许使用的最大内存 会平均分给每个分片
+	shardNumber    int                                                   // 缓存的分片数量 默认为1
+	lockedReads    bool                                                  // 为true时每次读取都拿写锁直接更新淘汰策略 不使用命中记录缓冲区
+	newPolicy      PolicyFactory                                         // 淘汰策略的构造函数 为nil时使用LRU
+	staleFor       time.Duration                                         // 缓存过期后继续保留的时间
+	onEntryDeleted func(key string, value ByteView, reason DeleteReason) // 当缓存被淘汰或过期删除时的回调函数
+
+	defaultTTL time.Duration // 缓存的默认存活时间 为0时永不过期
+
+	reaperInterval  time.Duration // 后台清理过期缓存的间隔 为0时不启动后台清理
+	reaperBatchSize int           // 后台清理每次最多处理的记录数
+
+	negativeTTL  time.Duration // ErrNotFound的缓存时间 为0时不缓存
+	refreshAhead time.Duration // 距离过期不足refreshAhead时 返回当前值的同时在后台刷新
+	refreshing   sync.Map      // 正在后台刷新的key 保证同一个key同时只有一次后台刷新
+
+	batchWindow  time.Duration // 合并未命中的时间窗口 getter实现了BatchGetter时生效 为0时不合并
+	maxBatchSize int           // 一次GetBatch最多加载的key数量
+	batcher      *batcher      // 合并未命中 为nil时逐个key调用getter
+
+	loaderTimeout time.Duration // 单次加载的超时时间 为0时不限制
+	logger        Logger        // 记录加载失败等信息
+
+	hotCacheEnabled    bool          // 是否开启热点缓存
+	hotCacheRatio      float64       // 热点缓存占cacheBytes的比例
+	hotCacheSampleRate int           // 从远程节点获取到的值 每hotCacheSampleRate次存入一次热点缓存
+	hotCacheTTL        time.Duration // 热点缓存中副本的存活时间 远程节点上的值被修改后 副本最多再被使用这么久
+
+	stats     groupStats // 统计数据
+	statsHook StatsHook  // 统计事件的回调函数 为nil时不回调
+
+	breakerConfig   BreakerConfig // 远程节点熔断器的配置
+	breakers        sync.Map      // 每个远程节点的熔断器 PeerCacheValueGetter -> *circuitBreaker
+	breakersVersion atomic.Uint64 // breakers对应的远程节点版本号 见PeerLister
+	peerFallback    PeerFallback  // 远程节点熔断或者请求失败时key的加载方式
 }
 
-// 全局变量
-var (
-	mu     sync.RWMutex              // 互斥锁的高级版本 读写锁 在原有的锁功能上 增加读写特性 当读锁锁定时 只会阻止写 同理当写锁锁定时 会同时阻止读写
-	groups = make(map[string]*Group) // 保存多个Group缓存
+const (
+	defaultReaperBatchSize = 100      // 后台清理每次默认最多处理的记录数
+	defaultCacheBytes      = 64 << 20 // 缓存默认允许使用的最大内存
+
+	defaultHotCacheSampleRate = 10          // 默认每10次从远程节点获取 存入一次热点缓存
+	defaultHotCacheTTL        = time.Minute // 热点缓存中副本默认的存活时间
 )
 
-// NewGroup 构造函数
-func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
-	if getter == nil {
-		panic("nil getter")
+// Logger 接口 Group通过它输出日志 *log.Logger实现了该接口
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// GroupConfig Group生效中的配置 由Group.Config返回 只用于查看 修改它不会影响Group
+type GroupConfig struct {
+	Name            string
+	CacheBytes      int64         // 缓存允许使用的最大内存
+	Shards          int           // 缓存的分片数量
+	BufferedReads   bool          // 是否使用命中记录缓冲区
+	EvictionPolicy  PolicyFactory // 淘汰策略的构造函数 为nil时使用LRU
+	DefaultTTL      time.Duration // 缓存的默认存活时间 为0时永不过期
+	NegativeTTL     time.Duration // ErrNotFound的缓存时间 为0时不缓存
+	RefreshAhead    time.Duration // 距离过期不足RefreshAhead时在后台刷新
+	StaleFor        time.Duration // 过期后继续作为旧值返回的时间
+	ReaperInterval  time.Duration // 后台清理过期缓存的间隔 为0时不启动后台清理
+	ReaperBatchSize int           // 后台清理每次最多处理的记录数
+	BatchWindow     time.Duration // 合并未命中的时间窗口
+	MaxBatchSize    int           // 一次GetBatch最多加载的key数量
+	LoaderTimeout   time.Duration // 单次加载的超时时间 为0时不限制
+	Logger          Logger
+
+	HotCacheRatio      float64       // 热点缓存占CacheBytes的比例 为0时不开启热点缓存
+	HotCacheSampleRate int           // 热点缓存的采样率
+	HotCacheTTL        time.Duration // 热点缓存中副本的存活时间
+
+	StatsHook StatsHook // 统计事件的回调函数
+
+	CircuitBreaker BreakerConfig // 远程节点熔断器的配置
+	PeerFallback   PeerFallback  // 远程节点熔断或者请求失败时key的加载方式
+}
+
+// GroupOption Group的可选配置项 在NewGroup中按顺序生效
+type GroupOption func(group *Group)
+
+// WithCacheBytes 指定缓存允许使用的最大内存 默认为64MB 开启分片时会平均分给每个分片
+func WithCacheBytes(cacheBytes int64) GroupOption {
+	return func(group *Group) {
+		group.cacheBytes = cacheBytes
 	}
-	mu.Lock()
-	defer mu.Unlock()
-	group := &Group{
-		name:      name,
-		getter:    getter,
-		mainCache: cache{cacheBytes: cacheBytes},
-		loader:    &singleflight.Group{},
+}
+
+// WithLoaderTimeout 指定单次加载（请求远程节点和调用getter）的超时时间 默认为0 即不限制
+// 调用方自己的ctx仍然生效 两者先到者为准 后台刷新和合并后的批量加载也受该超时时间限制
+func WithLoaderTimeout(timeout time.Duration) GroupOption {
+	return func(group *Group) {
+		group.loaderTimeout = timeout
+	}
+}
+
+// WithLogger 指定该Group使用的Logger 默认为log.Default()
+func WithLogger(logger Logger) GroupOption {
+	return func(group *Group) {
+		group.logger = logger
+	}
+}
+
+// WithHotCache 开启热点缓存 从远程节点获取到的值按1/sampleRate的概率存入本地的热点缓存
+// 热门的key被频繁请求 很快就会被采样到 之后的请求不再经过网络 冷门的key则很少进入热点缓存
+// 热点缓存占用cacheBytes的ratio 剩下的留给缓存主体 ratio必须在(0, 1)之间 否则NewGroup返回错误
+// sampleRate不为正数时使用默认值10
+// 远程节点上的值被修改后 热点缓存中的副本不会立刻失效 最多再被使用ttl 这也是副本的存活时间
+// ttl不为正数时使用默认值1分钟 Group的默认存活时间更短时使用默认存活时间
+func WithHotCache(ratio float64, sampleRate int, ttl time.Duration) GroupOption {
+	return func(group *Group) {
+		if sampleRate <= 0 {
+			sampleRate = defaultHotCacheSampleRate
+		}
+		if ttl <= 0 {
+			ttl = defaultHotCacheTTL
+		}
+		group.hotCacheRatio = ratio
+		group.hotCacheSampleRate = sampleRate
+		group.hotCacheTTL = ttl
+		group.hotCacheEnabled = true
+	}
+}
+
+// WithEvictionPolicy 指定该Group的缓存淘汰策略 默认为LRU
+func WithEvictionPolicy(factory PolicyFactory) GroupOption {
+	return func(group *Group) {
+		group.newPolicy = factory
+	}
+}
+
+// WithTTL 指定该Group中缓存的默认存活时间 默认为0 即永不过期
+func WithTTL(ttl time.Duration) GroupOption {
+	return func(group *Group) {
+		group.defaultTTL = ttl
+	}
+}
+
+// WithShards 把缓存拆分成shardNumber个分片 每个分片有自己的锁和cacheBytes/shardNumber的内存上限
+// 分片越多并发读写时锁的竞争越小 但每个分片能使用的内存也越少 默认为1
+func WithShards(shardNumber int) GroupOption {
+	return func(group *Group) {
+		group.shardNumber = shardNumber
+	}
+}
+
+// WithBufferedReads 是否使用命中记录缓冲区 默认开启
+// 开启时读取只需要读锁 命中记录攒成一批后再交给淘汰策略 关闭时每次读取都拿写锁 淘汰策略能看到每一次访问
+func WithBufferedReads(enabled bool) GroupOption {
+	return func(group *Group) {
+		group.lockedReads = !enabled
+	}
+}
+
+// WithExpiryReaper 为该Group启动后台清理协程 每隔interval删除最多batchSize条已经过期的缓存
+// batchSize不为正数时使用默认值 不再使用该Group时需要调用Stop
+func WithExpiryReaper(interval time.Duration, batchSize int) GroupOption {
+	return func(group *Group) {
+		if batchSize <= 0 {
+			batchSize = defaultReaperBatchSize
+		}
+		group.reaperInterval = interval
+		group.reaperBatchSize = batchSize
+	}
+}
+
+// WithOnEntryDeleted 设置缓存被淘汰或过期删除时的回调函数 回调函数在持有缓存锁时调用 不能再访问该Group
+func WithOnEntryDeleted(onEntryDeleted func(key string, value ByteView, reason DeleteReason)) GroupOption {
+	return func(group *Group) {
+		group.onEntryDeleted = onEntryDeleted
+	}
+}
+
+// WithStaleWhileRevalidate 设置软过期和硬过期的窗口
+// 距离过期不足refreshAhead时 缓存仍然算作新鲜 但会在后台提前刷新
+// 过期后staleFor以内 直接返回旧值并在后台刷新 超过staleFor后才视为未命中 调用方需要等待重新加载
+func WithStaleWhileRevalidate(refreshAhead, staleFor time.Duration) GroupOption {
+	return func(group *Group) {
+		group.refreshAhead = refreshAhead
+		group.staleFor = staleFor
+	}
+}
+
+// WithNegativeTTL 开启负缓存 Getter返回ErrNotFound时 该结果会被缓存ttl时长 期间再次请求该key直接返回ErrNotFound
+func WithNegativeTTL(ttl time.Duration) GroupOption {
+	return func(group *Group) {
+		group.negativeTTL = ttl
+	}
+}
+
+// WithBatchWindow 设置合并未命中的时间窗口 只在getter实现了BatchGetter时生效
+// window内并发到达的未命中会合并成一次GetBatch 攒够maxBatchSize个key时立刻发出 window不为正数时不合并
+// 默认不合并 因为每个未命中都要多等待window maxBatchSize不为正数时使用默认值100
+// getter同时实现了TTLGetter时不合并 GetBatch无法返回每个key的存活时间
+func WithBatchWindow(window time.Duration, maxBatchSize int) GroupOption {
+	return func(group *Group) {
+		group.batchWindow = window
+		group.maxBatchSize = maxBatchSize
+	}
+}
+
+// NewGroup 在默认的Registry中创建Group 同名的Group已经存在时panic 需要错误返回值时使用Registry.NewGroup
+// 所有配置都通过opts指定 未指定的配置使用默认值
+func NewGroup(name string, getter Getter, opts ...GroupOption) *Group {
+	group, err := DefaultRegistry.NewGroup(name, getter, opts...)
+	if err != nil {
+		panic(err)
 	}
-	groups[name] = group
 	return group
 }
 
-// GetGroup 获取Group缓存
+// GetGroup 从默认的Registry中获取Group缓存
 func GetGroup(name string) *Group {
-	mu.RLock()
-	g := groups[name]
-	mu.RUnlock()
-	return g
+	return DefaultRegistry.GetGroup(name)
 }
 
-// GetFromCache 从缓存中获取值
+// RemoveGroup 从默认的Registry中删除Group并停止它的后台协程 返回该Group是否存在
+func RemoveGroup(name string) bool {
+	return DefaultRegistry.RemoveGroup(name)
+}
+
+// newGroup Group的构造函数 不负责注册 配置项不合法时返回错误
+func newGroup(name string, getter Getter, opts ...GroupOption) (*Group, error) {
+	group := &Group{
+		name:       name,
+		getter:     getter,
+		cacheBytes: defaultCacheBytes,
+		loader:     &singleflight.Group{},
+
+		logger:        log.Default(),
+		breakerConfig: defaultBreakerConfig,
+	}
+	for _, opt := range opts {
+		opt(group)
+	}
+	if group.hotCacheEnabled && (group.hotCacheRatio <= 0 || group.hotCacheRatio >= 1) { // 否则缓存主体没有可用的内存
+		return nil, fmt.Errorf("hot cache ratio %v must be between 0 and 1", group.hotCacheRatio)
+	}
+	_, isTTLGetter := getter.(TTLGetter)
+	if batchGetter, ok := getter.(BatchGetter); ok && !isTTLGetter && group.batchWindow > 0 {
+		group.batcher = newBatcher(batchGetter, group.batchWindow, group.maxBatchSize, group.loaderTimeout)
+	}
+	mainBytes := group.cacheBytes
+	if group.hotCacheEnabled { // 从内存上限中分出一部分给热点缓存 热点缓存只有一个分片 不保留旧值 也不调用删除回调
+		hotBytes := int64(float64(group.cacheBytes) * group.hotCacheRatio)
+		mainBytes -= hotBytes
+		group.hotCache = newShardedCache(1, hotBytes, func(cacheBytes int64) *cache {
+			return newCache(cacheBytes, group.newPolicy, 0, !group.lockedReads, nil)
+		})
+	}
+	group.mainCache = newShardedCache(group.shardNumber, mainBytes, func(cacheBytes int64) *cache {
+		return newCache(cacheBytes, group.newPolicy, group.staleFor, !group.lockedReads, group.onEntryDeleted)
+	})
+	if group.reaperInterval > 0 {
+		group.mainCache.startJanitor(group.reaperInterval, group.reaperBatchSize)
+	}
+	return group, nil
+}
+
+// Name 返回该Group的名字
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Config 返回该Group生效中的配置
+func (g *Group) Config() GroupConfig {
+	return GroupConfig{
+		Name:            g.name,
+		CacheBytes:      g.cacheBytes,
+		Shards:          len(g.mainCache.shards),
+		BufferedReads:   !g.lockedReads,
+		EvictionPolicy:  g.newPolicy,
+		DefaultTTL:      g.defaultTTL,
+		NegativeTTL:     g.negativeTTL,
+		RefreshAhead:    g.refreshAhead,
+		StaleFor:        g.staleFor,
+		ReaperInterval:  g.reaperInterval,
+		ReaperBatchSize: g.reaperBatchSize,
+		BatchWindow:     g.batchWindow,
+		MaxBatchSize:    g.maxBatchSize,
+		LoaderTimeout:   g.loaderTimeout,
+		Logger:          g.logger,
+
+		HotCacheRatio:      g.hotCacheRatio,
+		HotCacheSampleRate: g.hotCacheSampleRate,
+		HotCacheTTL:        g.hotCacheTTL,
+
+		StatsHook: g.statsHook,
+
+		CircuitBreaker: g.breakerConfig,
+		PeerFallback:   g.peerFallback,
+	}
+}
+
+// Stop 停止该Group的后台协程 可以重复调用
+func (g *Group) Stop() {
+	g.mainCache.stop()
+}
+
+// GetFromCache 从缓存中获取值 等同于Get(context.Background(), key)
 func (g *Group) GetFromCache(key string) (ByteView, error) {
+	return g.Get(context.Background(), key)
+}
+
+// Get 从缓存中获取值 ctx结束时不再等待加载 直接返回ctx.Err()
+// ctx会被传递给Getter和远程节点的请求
+func (g *Group) Get(ctx context.Context, key string) (ByteView, error) {
 	if key == "" {
-		return ByteView{}, fmt.Errorf("key is required")
+		return ByteView{}, errKeyRequired
 	}
-	if v, isOk := g.mainCache.get(key); isOk { // 缓存命中
-		log.Println("[MisakaCache] hit")
+	g.record(EventGet, 0)
+	if v, isOk := g.lookupCache(key); isOk { // 缓存命中
+		if v.notFound { // 负缓存命中
+			return ByteView{}, fmt.Errorf("%s: %w", key, ErrNotFound)
+		}
 		return v, nil
 	}
 	// 缓存未命中 调用load函数
-	return g.load(key)
+	return g.load(ctx, key)
 }
 
-// load 缓存未命中时 从别的地方加载缓存
-func (g *Group) load(key string) (value ByteView, err error) {
-	viewi, err := g.loader.DoFunc(key, func() (interface{}, error) {
-		if g.peers != nil {
-			if peer, ok := g.peers.PickPeer(key); ok { // 先从存储着远程节点信息的HTTPPool中选出具体的远程节点
-				if value, err := g.getFromPeer(peer, key); err != nil { // 再根据这个具体的远程节点开始请求
-					return value, nil
-				}
-				log.Println("[MisakaCache] Failed to get from peer", err)
-			}
+// lookupCache 在本地缓存中查找 先查找缓存主体再查找热点缓存 缓存主体命中即将过期或者已经过期的旧值时在后台刷新
+func (g *Group) lookupCache(key string) (ByteView, bool) {
+	v, isOk := g.mainCache.get(key)
+	if isOk {
+		g.record(EventCacheHit, 0)
+		if !v.expire.IsZero() && !time.Now().Before(v.expire.Add(-g.refreshAhead)) {
+			g.refresh(key)
+		}
+		return v, isOk
+	}
+	if g.hotCache != nil { // 热点缓存中的值过期后直接视为未命中 重新从远程节点获取
+		if v, isOk = g.hotCache.get(key); isOk {
+			g.record(EventHotCacheHit, 0)
 		}
-		return g.getFromLocal(key)
+	}
+	return v, isOk
+}
+
+// load 缓存未命中时 从别的地方加载缓存
+func (g *Group) load(ctx context.Context, key string) (value ByteView, err error) {
+	var executed atomic.Bool // fn在另一个协程中执行 调用方可能提前返回 所以需要原子操作
+	viewi, err := g.loader.DoContext(ctx, key, func(ctx context.Context) (interface{}, error) {
+		executed.Store(true)
+		return g.loadOnce(ctx, key)
 	})
+	if !executed.Load() && ctx.Err() == nil { // 等到了别的调用方的加载结果
+		g.record(EventLoadDeduped, 0)
+	}
 
 	if err == nil {
 		return viewi.(ByteView), err
@@ -96,14 +384,89 @@ func (g *Group) load(key string) (value ByteView, err error) {
 	return
 }
 
+// loadOnce 实际的加载过程 先尝试远程节点 再从本地加载 调用方需要通过singleflight保证同一个key同时只加载一次
+// 熔断器打开的远程节点会被跳过 远程节点都不可用时按WithPeerFallback指定的方式加载
+func (g *Group) loadOnce(ctx context.Context, key string) (ByteView, error) {
+	loadCtx, cancel := g.loaderContext(ctx)
+	defer cancel()
+	var peerErr error
+	for _, peer := range g.pickPeers(key) { // 先从存储着远程节点信息的HTTPPool中选出具体的远程节点
+		breaker := g.breaker(peer)
+		if breaker != nil && !breaker.allow(time.Now()) {
+			g.record(EventPeerRejected, 0)
+			peerErr = errBreakerOpen
+			continue
+		}
+		value, err := g.getFromPeer(loadCtx, peer, key) // 再根据这个具体的远程节点开始请求
+		g.breakerDone(ctx, breaker, err)
+		if err == nil || !shouldFallback(err) { // 远程节点明确表示key不存在或者请求有问题时 不再从本地加载
+			return value, err
+		}
+		if loadCtx.Err() != nil { // 调用方已经不再等待 没有必要再从本地加载
+			return ByteView{}, loadCtx.Err()
+		}
+		g.logger.Printf("[MisakaCache] Failed to get from peer: %v", err)
+		peerErr = err
+	}
+	if peerErr != nil && g.peerFallback == FallbackError {
+		return ByteView{}, peerErr
+	}
+	return g.getFromLocal(loadCtx, key)
+}
+
+// loaderContext 为一次加载加上超时时间
+func (g *Group) loaderContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.loaderTimeout > 0 {
+		return context.WithTimeout(ctx, g.loaderTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// refresh 在后台重新加载缓存 同一个key同时只会有一次后台刷新 并且和未命中时的加载共用singleflight
+// 后台刷新不属于任何一个调用方 所以使用context.Background()
+func (g *Group) refresh(key string) {
+	if _, loaded := g.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	go func() {
+		defer g.refreshing.Delete(key)
+		if _, err := g.load(context.Background(), key); err != nil {
+			g.logger.Printf("[MisakaCache] Failed to refresh %s: %v", key, err)
+		}
+	}()
+}
+
 // getFromLocal 从本地加载缓存 在这里调用Getter的Get函数 并且通过populateCache存入缓存
-func (g *Group) getFromLocal(key string) (ByteView, error) {
-	bytes, err := g.getter.Get(key)
+func (g *Group) getFromLocal(ctx context.Context, key string) (ByteView, error) {
+	var (
+		bytes []byte
+		ttl   time.Duration
+		err   error
+	)
+	start := time.Now()
+	if g.batcher != nil { // 和其他未命中合并成一次GetBatch
+		bytes, err = g.batcher.get(ctx, key)
+	} else if ttlGetter, ok := g.getter.(TTLGetter); ok {
+		bytes, ttl, err = ttlGetter.GetWithTTL(ctx, key)
+	} else {
+		bytes, err = g.getter.Get(ctx, key)
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		g.record(EventLoadError, time.Since(start))
+		return ByteView{}, err
+	}
+	g.record(EventLocalLoad, time.Since(start))
 	if err != nil {
+		if g.negativeTTL > 0 { // 负缓存
+			g.populateCache(key, ByteView{notFound: true, expire: expireAfter(g.negativeTTL)})
+		}
 		return ByteView{}, err
 	}
+	if ttl == 0 {
+		ttl = g.defaultTTL
+	}
 
-	value := ByteView{cacheBytes: cloneBytes(bytes)}
+	value := ByteView{cacheBytes: cloneBytes(bytes), expire: expireAfter(ttl)}
 	g.populateCache(key, value)
 	return value, nil
 }
@@ -113,6 +476,116 @@ func (g *Group) populateCache(key string, value ByteView) {
 	g.mainCache.add(key, value)
 }
 
+// populateHotCache 按采样率把从远程节点获取到的值存入热点缓存
+func (g *Group) populateHotCache(key string, value ByteView) {
+	if g.hotCache == nil || rand.IntN(g.hotCacheSampleRate) != 0 {
+		return
+	}
+	ttl := g.hotCacheTTL
+	if g.defaultTTL > 0 && g.defaultTTL < ttl {
+		ttl = g.defaultTTL
+	}
+	value.expire = expireAfter(ttl)
+	g.hotCache.add(key, value)
+}
+
+// Set 把key对应的值写入缓存 ttl为0时使用默认存活时间 小于0时永不过期
+// key属于远程节点时写入该远程节点 用于在修改数据源之后同步更新缓存
+func (g *Group) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if key == "" {
+		return errKeyRequired
+	}
+	peer, err := g.pickWriter(key)
+	if err != nil {
+		return err
+	}
+	if peer != nil {
+		g.dropLocalCopies(key, false) // 本地的副本已经过时了
+		return peer.SetCacheToPeer(ctx, &pb.SetRequest{
+			Group: g.name,
+			Key:   key,
+			Value: value,
+			TtlMs: ttl.Milliseconds(),
+		})
+	}
+	g.setLocally(key, value, ttl)
+	return nil
+}
+
+// Remove 删除key对应的缓存 之后的读取会重新调用Getter加载 key属于远程节点时在该远程节点上删除
+func (g *Group) Remove(ctx context.Context, key string) error {
+	return g.removeFromOwner(ctx, key, false)
+}
+
+// Invalidate 把key对应的缓存标记为过期 key属于远程节点时在该远程节点上标记
+// 和Remove不同 开启了WithStaleWhileRevalidate时 在staleFor以内仍然会返回旧值并在后台刷新
+func (g *Group) Invalidate(ctx context.Context, key string) error {
+	return g.removeFromOwner(ctx, key, true)
+}
+
+// removeFromOwner Remove和Invalidate的公共部分
+func (g *Group) removeFromOwner(ctx context.Context, key string, invalidate bool) error {
+	if key == "" {
+		return errKeyRequired
+	}
+	peer, err := g.pickWriter(key)
+	if err != nil {
+		return err
+	}
+	if peer != nil {
+		g.dropLocalCopies(key, invalidate)
+		return peer.RemoveCacheFromPeer(ctx, &pb.RemoveRequest{
+			Group:      g.name,
+			Key:        key,
+			Invalidate: invalidate,
+		})
+	}
+	g.removeLocally(key, invalidate)
+	return nil
+}
+
+// pickWriter 挑选key所属的远程节点 key属于本节点时返回nil
+func (g *Group) pickWriter(key string) (PeerCacheValueWriter, error) {
+	if g.peers == nil {
+		return nil, nil
+	}
+	peer, ok := g.peers.PickPeer(key)
+	if !ok {
+		return nil, nil
+	}
+	writer, ok := peer.(PeerCacheValueWriter)
+	if !ok {
+		return nil, fmt.Errorf("peer of key %s does not support writes", key)
+	}
+	return writer, nil
+}
+
+// setLocally 写入本节点的缓存 不再挑选远程节点 远程节点发来的写入请求也走这里
+func (g *Group) setLocally(key string, value []byte, ttl time.Duration) {
+	if ttl == 0 {
+		ttl = g.defaultTTL
+	}
+	g.populateCache(key, ByteView{cacheBytes: cloneBytes(value), expire: expireAfter(ttl)})
+}
+
+// removeLocally 删除本节点的缓存或者把它标记为过期 不再挑选远程节点
+func (g *Group) removeLocally(key string, invalidate bool) {
+	if invalidate {
+		g.mainCache.invalidate(key, time.Now())
+		return
+	}
+	g.mainCache.delete(key)
+}
+
+// dropLocalCopies 删除key属于远程节点时留在本节点的副本
+// 热点缓存中的副本 以及远程节点不可用时从本地加载后写入mainCache的值
+func (g *Group) dropLocalCopies(key string, invalidate bool) {
+	if g.hotCache != nil {
+		g.hotCache.delete(key)
+	}
+	g.removeLocally(key, invalidate)
+}
+
 // RegisterPeers 将初始化完成的HTTPPool注入到group中 仅一次
 func (g *Group) RegisterPeers(peers PeerPicker) {
 	if g.peers != nil {
@@ -122,7 +595,7 @@ func (g *Group) RegisterPeers(peers PeerPicker) {
 }
 
 // getFromPeer 从远程节点获得缓存
-func (g *Group) getFromPeer(peer PeerCacheValueGetter, key string) (ByteView, error) {
+func (g *Group) getFromPeer(ctx context.Context, peer PeerCacheValueGetter, key string) (ByteView, error) {
 	req := &pb.Request{
 		Key:   key,
 		Group: g.name,
@@ -130,9 +603,17 @@ func (g *Group) getFromPeer(peer PeerCacheValueGetter, key string) (ByteView, er
 
 	resp := &pb.Response{}
 
-	err := peer.GetCacheFromPeer(req, resp)
+	start := time.Now()
+	err := peer.GetCacheFromPeer(ctx, req, resp)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		g.record(EventPeerError, time.Since(start))
+		return ByteView{}, err
+	}
+	g.record(EventPeerLoad, time.Since(start))
 	if err != nil {
 		return ByteView{}, err
 	}
-	return ByteView{cacheBytes: resp.Value}, nil
+	value := ByteView{cacheBytes: resp.Value}
+	g.populateHotCache(key, value)
+	return value, nil
 }
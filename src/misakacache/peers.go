@@ -1,13 +1,43 @@
 package misakacache
 
-import pb "MisakaCache/src/misakacache/misakacachepb"
+import (
+	pb "MisakaCache/src/misakacache/misakacachepb"
+	"context"
+)
 
 // PeerPicker 接口 根据key挑选远程节点
 type PeerPicker interface {
 	PickPeer(key string) (peerGetter PeerCacheValueGetter, ok bool)
 }
 
-// PeerCacheValueGetter 接口 根据key和给定的group获取缓存值
+// PeerListPicker 接口 按一致性哈希环上的顺序挑选多个远程节点 PeerPicker可以选择实现该接口
+// 没有实现该接口时 FallbackNextPeer退化为FallbackLocal
+type PeerListPicker interface {
+	// PickPeers 返回环上从key所属节点开始的最多n个不同的远程节点 遇到自身节点时停止 之后的节点由本地加载代替
+	PickPeers(key string, n int) []PeerCacheValueGetter
+}
+
+// PeerLister 接口 列出当前所有的远程节点 PeerPicker可以选择实现该接口
+// Group据此清理已经被移除的远程节点的熔断器
+type PeerLister interface {
+	Peers() []PeerCacheValueGetter // 当前所有的远程节点
+	PeersVersion() uint64          // 远程节点的版本号 每次更新远程节点后增加
+}
+
+// PeerCacheValueGetter 接口 根据key和给定的group获取缓存值 ctx结束时请求应当被取消
 type PeerCacheValueGetter interface {
-	GetCacheFromPeer(in *pb.Request, out *pb.Response) error
+	GetCacheFromPeer(ctx context.Context, in *pb.Request, out *pb.Response) error
+}
+
+// PeerCacheValueWriter 接口 在远程节点上写入或删除缓存 PickPeer返回的远程节点可以选择实现该接口
+// 没有实现该接口的远程节点不支持Group.Set/Remove/Invalidate
+type PeerCacheValueWriter interface {
+	SetCacheToPeer(ctx context.Context, in *pb.SetRequest) error
+	RemoveCacheFromPeer(ctx context.Context, in *pb.RemoveRequest) error
+}
+
+// PeerBatchGetter 接口 一次请求从远程节点获取多个key的缓存值 PickPeer返回的远程节点可以选择实现该接口
+// 没有实现该接口的远程节点在GetMany中退化为逐个key请求
+type PeerBatchGetter interface {
+	GetManyFromPeer(ctx context.Context, in *pb.BatchRequest, out *pb.BatchResponse) error
 }
@@ -0,0 +1,48 @@
+package misakacache
+
+import (
+	"MisakaCache/src/misakacache/arc"
+	"MisakaCache/src/misakacache/lfu"
+	"MisakaCache/src/misakacache/lru"
+	"MisakaCache/src/misakacache/tinylfu"
+)
+
+// EvictionPolicy 接口 规定了缓存淘汰策略需要提供的方法 cache通过该接口操作具体的淘汰策略 而不再固定使用LRU
+type EvictionPolicy interface {
+	GetValue(key string) (value lru.Value, isOk bool)         // 查找缓存 同时记录一次访问
+	SetValue(key string, value lru.Value)                     // 添加/修改缓存 内存超出上限时按策略淘汰
+	RemoveValue(key string) bool                              // 主动删除缓存 不触发淘汰回调
+	Len() int                                                 // 当前已缓存的键值对数量
+	BytesUsed() int64                                         // 当前已经使用的内存
+	OnEvict(onEntryDeleted func(key string, value lru.Value)) // 设置缓存被淘汰时的回调函数
+}
+
+// PolicyFactory 淘汰策略的构造函数类型 cache懒加载时通过它创建具体的淘汰策略
+type PolicyFactory func(maxMemoryBytes int64) EvictionPolicy
+
+// LRUPolicy 默认的淘汰策略 LRU
+func LRUPolicy(maxMemoryBytes int64) EvictionPolicy {
+	return lru.NewLRU(maxMemoryBytes, nil)
+}
+
+// LFUPolicy LFU淘汰策略 适合热点集合稳定的缓存
+func LFUPolicy(maxMemoryBytes int64) EvictionPolicy {
+	return lfu.NewLFU(maxMemoryBytes, nil)
+}
+
+// ARCPolicy ARC淘汰策略 能够自适应地抵抗一次性的扫描
+func ARCPolicy(maxMemoryBytes int64) EvictionPolicy {
+	return arc.NewARC(maxMemoryBytes, nil)
+}
+
+// TinyLFUPolicy W-TinyLFU淘汰策略 新键只有比淘汰对象更热门时才能进入缓存 防止只访问一次的键冲掉热点缓存
+func TinyLFUPolicy(maxMemoryBytes int64) EvictionPolicy {
+	return tinylfu.NewTinyLFU(maxMemoryBytes, nil)
+}
+
+var (
+	_ EvictionPolicy = (*lru.LRU)(nil) // 检查接口是否被完整实现
+	_ EvictionPolicy = (*lfu.LFU)(nil)
+	_ EvictionPolicy = (*arc.ARC)(nil)
+	_ EvictionPolicy = (*tinylfu.TinyLFU)(nil)
+)
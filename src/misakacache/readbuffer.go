@@ -0,0 +1,51 @@
+package misakacache
+
+import (
+	"math/rand/v2"
+	"sync"
+)
+
+const (
+	readBufferStripes    = 16 // 缓冲区的条带数量 读取时随机选择一个条带 降低条带之间的竞争
+	readBufferStripeSize = 64 // 每个条带攒够多少条命中记录后交给淘汰策略
+)
+
+// readBuffer 有损的命中记录缓冲区 参考了Caffeine和Ristretto的做法
+// 读取命中后只把key追加进一个条带 条带满了之后整批交给淘汰策略
+// 条带被其他协程占用时 命中记录会被直接丢弃 只有条带满了的那次读取需要等待写锁
+type readBuffer struct {
+	stripes [readBufferStripes]readStripe
+}
+
+// readStripe 缓冲区的一个条带
+type readStripe struct {
+	mutex sync.Mutex
+	keys  []string
+	_     [32]byte // 填充 避免相邻条带落在同一个缓存行上
+}
+
+// newReadBuffer readBuffer的构造函数
+func newReadBuffer() *readBuffer {
+	buffer := &readBuffer{}
+	for i := range buffer.stripes {
+		buffer.stripes[i].keys = make([]string, 0, readBufferStripeSize)
+	}
+	return buffer
+}
+
+// push 记录一次命中 条带满了的时候返回整批命中记录 否则返回nil
+func (b *readBuffer) push(key string) []string {
+	stripe := &b.stripes[rand.IntN(readBufferStripes)]
+	if !stripe.mutex.TryLock() { // 条带正被占用 丢弃这次记录
+		return nil
+	}
+	defer stripe.mutex.Unlock()
+
+	stripe.keys = append(stripe.keys, key)
+	if len(stripe.keys) < readBufferStripeSize {
+		return nil
+	}
+	keys := stripe.keys
+	stripe.keys = make([]string, 0, readBufferStripeSize)
+	return keys
+}
@@ -0,0 +1,83 @@
+package misakacache
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrGroupExists 在同一个Registry中创建同名的Group时返回该错误
+var ErrGroupExists = errors.New("group already exists")
+
+// Registry 管理一组Group和对应的节点池 不同的Registry之间互不影响
+// 同一个进程中可以存在多个相互隔离的缓存 测试也可以为每个用例创建新的Registry
+type Registry struct {
+	mu     sync.RWMutex      // 保护groups
+	groups map[string]*Group // 保存多个Group缓存
+}
+
+// DefaultRegistry 默认的Registry 包级别的NewGroup、GetGroup、RemoveGroup和NewHTTPPool都使用它
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry Registry的构造函数
+func NewRegistry() *Registry {
+	return &Registry{groups: make(map[string]*Group)}
+}
+
+// NewGroup 创建Group并注册到该Registry 同名的Group已经存在时返回ErrGroupExists 配置项不合法时也返回错误
+func (r *Registry) NewGroup(name string, getter Getter, opts ...GroupOption) (*Group, error) {
+	if getter == nil {
+		return nil, errors.New("nil getter")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.groups[name]; ok {
+		return nil, fmt.Errorf("%s: %w", name, ErrGroupExists)
+	}
+	group, err := newGroup(name, getter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	r.groups[name] = group
+	return group, nil
+}
+
+// GetGroup 获取Group缓存 不存在时返回nil
+func (r *Registry) GetGroup(name string) *Group {
+	r.mu.RLock()
+	g := r.groups[name]
+	r.mu.RUnlock()
+	return g
+}
+
+// Groups 返回该Registry中的所有Group 按名字排序
+func (r *Registry) Groups() []*Group {
+	r.mu.RLock()
+	groups := make([]*Group, 0, len(r.groups))
+	for _, group := range r.groups {
+		groups = append(groups, group)
+	}
+	r.mu.RUnlock()
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
+// RemoveGroup 删除Group并停止它的后台协程 返回该Group是否存在 删除之后可以再创建同名的Group
+func (r *Registry) RemoveGroup(name string) bool {
+	r.mu.Lock()
+	group, ok := r.groups[name]
+	delete(r.groups, name)
+	r.mu.Unlock()
+	if ok {
+		group.Stop()
+	}
+	return ok
+}
+
+// NewHTTPPool 创建一个只为该Registry中的Group提供服务的HTTPPool
+func (r *Registry) NewHTTPPool(selfAddr string, opts ...HTTPPoolOption) *HTTPPool {
+	pool := NewHTTPPool(selfAddr, opts...)
+	pool.registry = r
+	return pool
+}
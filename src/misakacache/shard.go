@@ -0,0 +1,118 @@
+package misakacache
+
+import (
+	"sync"
+	"time"
+)
+
+// shardedCache 把缓存按key的哈希值拆分成多个分片 每个分片有自己的锁和自己的一份内存上限
+// 不同分片上的读写互不阻塞 用来代替整个Group共用一把锁
+type shardedCache struct {
+	shards []*cache
+
+	stopJanitor chan struct{}  // 关闭后通知后台清理协程退出
+	janitorWG   sync.WaitGroup // 等待后台清理协程退出
+	stopOnce    sync.Once
+}
+
+// newShardedCache shardedCache的构造函数 cacheBytes会平均分给每个分片 create用于创建单个分片
+func newShardedCache(shardNumber int, cacheBytes int64, create func(cacheBytes int64) *cache) *shardedCache {
+	if shardNumber < 1 {
+		shardNumber = 1
+	}
+	sc := &shardedCache{shards: make([]*cache, shardNumber)}
+	for i := range sc.shards {
+		sc.shards[i] = create(cacheBytes / int64(shardNumber))
+	}
+	return sc
+}
+
+// shard 根据key选择分片 使用FNV-1a哈希 这里手写是为了避免每次读写都分配一个hash.Hash
+func (sc *shardedCache) shard(key string) *cache {
+	if len(sc.shards) == 1 {
+		return sc.shards[0]
+	}
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return sc.shards[hash%uint32(len(sc.shards))]
+}
+
+// add 写入key所在的分片
+func (sc *shardedCache) add(key string, value ByteView) {
+	sc.shard(key).add(key, value)
+}
+
+// get 从key所在的分片读取
+func (sc *shardedCache) get(key string) (value ByteView, isOk bool) {
+	return sc.shard(key).get(key)
+}
+
+// delete 删除key所在分片中的缓存
+func (sc *shardedCache) delete(key string) bool {
+	return sc.shard(key).delete(key)
+}
+
+// invalidate 把key所在分片中的缓存标记为过期
+func (sc *shardedCache) invalidate(key string, now time.Time) bool {
+	return sc.shard(key).invalidate(key, now)
+}
+
+// stats 汇总所有分片的统计数据
+func (sc *shardedCache) stats() (stats CacheStats) {
+	for _, shard := range sc.shards {
+		shardStats := shard.stats()
+		stats.Bytes += shardStats.Bytes
+		stats.Items += shardStats.Items
+		stats.Gets += shardStats.Gets
+		stats.Hits += shardStats.Hits
+		stats.Evictions += shardStats.Evictions
+	}
+	return
+}
+
+// removeExpired 依次清理每个分片的过期缓存 batchSize平均分给每个分片 返回实际删除的缓存数量
+func (sc *shardedCache) removeExpired(now time.Time, batchSize int) (removed int) {
+	perShard := (batchSize + len(sc.shards) - 1) / len(sc.shards)
+	for _, shard := range sc.shards {
+		removed += shard.removeExpired(now, perShard)
+	}
+	return
+}
+
+// startJanitor 启动后台清理协程 每隔interval删除最多batchSize条过期缓存 期间每个分片都会维护过期堆
+func (sc *shardedCache) startJanitor(interval time.Duration, batchSize int) {
+	for _, shard := range sc.shards {
+		shard.trackExpiry(true)
+	}
+	sc.stopJanitor = make(chan struct{})
+	sc.janitorWG.Add(1)
+	go func() {
+		defer sc.janitorWG.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				sc.removeExpired(now, batchSize)
+			case <-sc.stopJanitor:
+				return
+			}
+		}
+	}()
+}
+
+// stop 停止后台清理协程并等待其退出 可以重复调用
+func (sc *shardedCache) stop() {
+	sc.stopOnce.Do(func() {
+		if sc.stopJanitor != nil {
+			close(sc.stopJanitor)
+			sc.janitorWG.Wait()
+			for _, shard := range sc.shards { // 后台清理已经退出 过期堆不再需要
+				shard.trackExpiry(false)
+			}
+		}
+	})
+}
@@ -1,12 +1,17 @@
 package singleflight
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"sync"
+)
 
 // call 含义为正在进行中 或者是已经结束的请求
 type call struct {
-	wg  sync.WaitGroup // 避免重入
-	val interface{}
-	err error
+	done chan struct{}   // 请求结束后关闭 等待者可以同时等待ctx 避免一直阻塞
+	ctx  context.Context // 发起调用的调用方的ctx 通过Acquire登记的调用为nil
+	val  interface{}
+	err  error
 }
 
 // Group 对call进行管理
@@ -17,30 +22,81 @@ type Group struct {
 
 // DoFunc 要保护的方法调用入口 针对同样的key 传入的fn只会被调用一次 直到第一次fn的调用完成
 func (g *Group) DoFunc(key string, fn func() (interface{}, error)) (interface{}, error) {
-	g.mu.Lock() // 加锁
+	return g.DoContext(context.Background(), key, func(context.Context) (interface{}, error) {
+		return fn()
+	})
+}
 
-	if g.m == nil { // 如果没初始化的话先初始化
-		g.m = make(map[string]*call)
-	}
+// DoContext 和DoFunc相同 但是等待中的调用方在ctx结束时会立刻返回ctx.Err() 不再等待fn完成
+// fn使用第一个调用方的ctx 如果它因为第一个调用方的ctx结束而失败 而其他调用方的ctx仍然有效 则由其他调用方重新发起调用
+// fn自己超时（例如加载超时）导致的失败不会重新发起 所有调用方共享这个结果
+func (g *Group) DoContext(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	for {
+		g.mu.Lock() // 加锁
+
+		if g.m == nil { // 如果没初始化的话先初始化
+			g.m = make(map[string]*call)
+		}
 
-	if c, ok := g.m[key]; ok { // 已经存在之前的请求 则等待之前的请求完成 直接返回之前的请求结果
-		g.mu.Unlock() // 读m完成 解锁
-		c.wg.Wait()   // 等待信号量归零
+		c, ok := g.m[key]
+		if !ok { // 如果不存在相同的请求 则新增请求
+			c = &call{done: make(chan struct{}), ctx: ctx}
+			g.m[key] = c // 写入m
+			go g.doCall(ctx, key, c, fn)
+		}
+		g.mu.Unlock() // 读写m完成 解锁
+
+		select {
+		case <-c.done: // 等待请求完成
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if ok && c.canceledByCaller() && ctx.Err() == nil { // 请求是被别的调用方的ctx取消的 重新发起
+			continue
+		}
 		return c.val, c.err
 	}
+}
 
-	// 如果不存在相同的请求 则新增请求
-	c := new(call)
-	c.wg.Add(1)   // 开始调用 信号量+1
-	g.m[key] = c  // 写入m
-	g.mu.Unlock() // 读写m完成 解锁
+// Acquire 如果key没有正在进行的调用 则登记一个新的调用 由调用方自己加载并通过finish发布结果 ok为true
+// 在finish之前 同一个key的DoContext都会等待这个结果 用于调用方一次加载多个key的情况 finish必须被调用且只能调用一次
+// 如果key已经有正在进行的调用 ok为false 调用方应该通过DoContext等待它
+func (g *Group) Acquire(key string) (finish func(val interface{}, err error), ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	if _, ok = g.m[key]; ok {
+		return nil, false
+	}
+	c := &call{done: make(chan struct{})}
+	g.m[key] = c
+	return func(val interface{}, err error) {
+		c.val, c.err = val, err
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+		close(c.done)
+	}, true
+}
 
-	c.val, c.err = fn() // 实际调用
-	c.wg.Done()         // 调用已完成 信号量-1
+// doCall 实际调用fn 调用结束后通知所有等待者
+func (g *Group) doCall(ctx context.Context, key string, c *call, fn func(ctx context.Context) (interface{}, error)) {
+	c.val, c.err = fn(ctx) // 实际调用
 
 	g.mu.Lock()      // 加锁
 	delete(g.m, key) // 请求结束 删除
 	g.mu.Unlock()    // 写入m完成 解锁
 
-	return c.val, c.err
+	close(c.done) // 调用已完成 通知等待者
+}
+
+// canceledByCaller 判断调用失败是否是因为发起调用的调用方的ctx结束了
+func (c *call) canceledByCaller() bool {
+	if c.ctx == nil || c.ctx.Err() == nil {
+		return false
+	}
+	return errors.Is(c.err, context.Canceled) || errors.Is(c.err, context.DeadlineExceeded)
 }
@@ -0,0 +1,174 @@
+package misakacache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// StatsEvent Group统计的事件类型 通过WithStatsHook可以拿到每一次事件
+type StatsEvent int
+
+const (
+	EventGet          StatsEvent = iota // 一次读取请求 GetMany中每个key算一次
+	EventCacheHit                       // 缓存主体命中
+	EventHotCacheHit                    // 热点缓存命中
+	EventPeerLoad                       // 从远程节点加载成功（包括远程节点返回ErrNotFound） 批量请求中每个key算一次
+	EventPeerError                      // 从远程节点加载失败
+	EventLocalLoad                      // 调用getter加载成功（包括返回ErrNotFound） 批量加载中每个key算一次
+	EventLoadError                      // 调用getter加载失败
+	EventLoadDeduped                    // 加载被singleflight合并 没有实际执行
+	EventPeerRejected                   // 远程节点的熔断器打开 请求没有发出
+	eventNumber
+)
+
+// String 返回事件的字符串表示
+func (event StatsEvent) String() string {
+	switch event {
+	case EventGet:
+		return "gets"
+	case EventCacheHit:
+		return "cache_hits"
+	case EventHotCacheHit:
+		return "hot_cache_hits"
+	case EventPeerLoad:
+		return "peer_loads"
+	case EventPeerError:
+		return "peer_errors"
+	case EventLocalLoad:
+		return "local_loads"
+	case EventLoadError:
+		return "load_errors"
+	case EventLoadDeduped:
+		return "loads_deduped"
+	case EventPeerRejected:
+		return "peer_rejected"
+	default:
+		return "unknown"
+	}
+}
+
+// StatsHook 统计事件的回调函数 latency只在加载类的事件中有值 回调函数会在读取的路径上同步调用 不能阻塞
+type StatsHook func(group string, event StatsEvent, latency time.Duration)
+
+// WithStatsHook 设置统计事件的回调函数 可以用来对接外部的监控系统
+func WithStatsHook(hook StatsHook) GroupOption {
+	return func(group *Group) {
+		group.statsHook = hook
+	}
+}
+
+// GroupStats Group的统计数据 由Group.Stats返回
+type GroupStats struct {
+	Gets         int64 // 读取请求的数量
+	CacheHits    int64 // 缓存主体命中的数量
+	HotCacheHits int64 // 热点缓存命中的数量
+	PeerLoads    int64 // 从远程节点加载成功的次数
+	PeerErrors   int64 // 从远程节点加载失败的次数
+	LocalLoads   int64 // 调用getter加载成功的次数
+	LoadErrors   int64 // 调用getter加载失败的次数
+	LoadsDeduped int64 // 被singleflight合并的加载次数
+	PeerRejected int64 // 因为熔断器打开而没有发往远程节点的请求次数
+
+	Evictions int64 // 缓存主体和热点缓存一共淘汰的缓存数量
+	Bytes     int64 // 缓存主体和热点缓存一共使用的内存
+	Items     int64 // 缓存主体和热点缓存一共缓存的键值对数量
+}
+
+// CacheType 缓存的类型 用于Group.CacheStats
+type CacheType int
+
+const (
+	MainCache CacheType = iota // 缓存主体 保存属于本节点的key
+	HotCache                   // 热点缓存 保存从远程节点获取到的热门key
+)
+
+// CacheStats 单个缓存的统计数据
+type CacheStats struct {
+	Bytes     int64 // 使用的内存
+	Items     int64 // 缓存的键值对数量
+	Gets      int64 // 读取次数
+	Hits      int64 // 命中次数
+	Evictions int64 // 被淘汰策略淘汰的数量 不包括过期和主动删除
+}
+
+// groupStats Group内部的计数器 所有字段都是原子操作
+type groupStats struct {
+	counters    [eventNumber]atomic.Int64
+	peerLatency latencyHistogram // 远程节点请求的耗时 包括失败的请求
+}
+
+// latencyBuckets 耗时直方图的桶上限 单位秒
+var latencyBuckets = [...]float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// latencyHistogram 耗时直方图 每个桶只记录落在该桶里的次数 输出时再累加
+type latencyHistogram struct {
+	buckets [len(latencyBuckets)]atomic.Int64
+	count   atomic.Int64
+	sum     atomic.Int64 // 总耗时 单位纳秒
+}
+
+// observe 记录一次耗时
+func (h *latencyHistogram) observe(latency time.Duration) {
+	seconds := latency.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.buckets[i].Add(1)
+			break
+		}
+	}
+	h.count.Add(1)
+	h.sum.Add(int64(latency))
+}
+
+// record 记录一次事件 并调用回调函数
+func (g *Group) record(event StatsEvent, latency time.Duration) {
+	g.stats.counters[event].Add(1)
+	if event == EventPeerLoad || event == EventPeerError {
+		g.stats.peerLatency.observe(latency)
+	}
+	if g.statsHook != nil {
+		g.statsHook(g.name, event, latency)
+	}
+}
+
+// recordEach 批量加载时为每个key记录一次事件 和逐个key的Get保持一致
+func (g *Group) recordEach(event StatsEvent, latency time.Duration, keys int) {
+	for i := 0; i < keys; i++ {
+		g.record(event, latency)
+	}
+}
+
+// Stats 返回该Group的统计数据
+func (g *Group) Stats() GroupStats {
+	main := g.CacheStats(MainCache)
+	hot := g.CacheStats(HotCache)
+	counters := &g.stats.counters
+	return GroupStats{
+		Gets:         counters[EventGet].Load(),
+		CacheHits:    counters[EventCacheHit].Load(),
+		HotCacheHits: counters[EventHotCacheHit].Load(),
+		PeerLoads:    counters[EventPeerLoad].Load(),
+		PeerErrors:   counters[EventPeerError].Load(),
+		LocalLoads:   counters[EventLocalLoad].Load(),
+		LoadErrors:   counters[EventLoadError].Load(),
+		LoadsDeduped: counters[EventLoadDeduped].Load(),
+		PeerRejected: counters[EventPeerRejected].Load(),
+
+		Evictions: main.Evictions + hot.Evictions,
+		Bytes:     main.Bytes + hot.Bytes,
+		Items:     main.Items + hot.Items,
+	}
+}
+
+// CacheStats 返回指定缓存的统计数据 没有开启热点缓存时HotCache的统计数据全部为0
+func (g *Group) CacheStats(which CacheType) CacheStats {
+	switch which {
+	case MainCache:
+		return g.mainCache.stats()
+	case HotCache:
+		if g.hotCache != nil {
+			return g.hotCache.stats()
+		}
+	}
+	return CacheStats{}
+}
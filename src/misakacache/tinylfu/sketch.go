@@ -0,0 +1,152 @@
+package tinylfu
+
+import "hash/fnv"
+
+/*
+Count-Min Sketch 用很小的内存近似统计每个键的访问次数
+
+sketch由depth行计数器组成 每一行用不同的哈希函数把键映射到一个计数器上
+记录访问时把每一行对应的计数器都+1 估计访问次数时取所有行中最小的那个计数器
+哈希冲突只会让计数偏大 取最小值能把这种偏差降到最低
+
+计数器只用一个字节 并且最大只记到maxCount 当记录的访问次数达到采样上限时 所有计数器减半
+这样旧的访问次数会逐渐衰减 和LFU的频率衰减是同样的目的
+*/
+
+const (
+	sketchDepth = 4  // 计数器的行数
+	maxCount    = 15 // 计数器的上限 相当于4位计数器
+)
+
+// hashKey 计算键的64位哈希值 sketch和doorkeeper都从这个哈希值派生出各自需要的哈希
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// countMinSketch 频率估计器
+type countMinSketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint64 // 每行的计数器数量是2的幂 用掩码代替取模
+	additions  int    // 距离上一次衰减记录的访问次数
+	sampleSize int    // 采样上限 达到后所有计数器减半
+}
+
+// newCountMinSketch countMinSketch的构造函数 counters为每行的计数器数量 会被向上取整为2的幂
+func newCountMinSketch(counters int) *countMinSketch {
+	width := nextPowerOfTwo(counters)
+	sketch := &countMinSketch{
+		mask:       uint64(width - 1),
+		sampleSize: 10 * width,
+	}
+	for i := range sketch.rows {
+		sketch.rows[i] = make([]uint8, width)
+	}
+	return sketch
+}
+
+// index 第row行中键对应的计数器下标 用双重哈希派生每一行的哈希函数
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	h1, h2 := hash, (hash>>32)|(hash<<32)
+	return (h1 + uint64(row)*h2) & s.mask
+}
+
+// increment 记录一次访问 返回是否触发了衰减
+func (s *countMinSketch) increment(hash uint64) (reset bool) {
+	for row := range s.rows {
+		i := s.index(hash, row)
+		if s.rows[row][i] < maxCount {
+			s.rows[row][i]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+		return true
+	}
+	return false
+}
+
+// estimate 估计访问次数 取所有行中最小的计数器
+func (s *countMinSketch) estimate(hash uint64) int {
+	minimum := uint8(maxCount)
+	for row := range s.rows {
+		if count := s.rows[row][s.index(hash, row)]; count < minimum {
+			minimum = count
+		}
+	}
+	return int(minimum)
+}
+
+// reset 所有计数器减半
+func (s *countMinSketch) reset() {
+	s.additions /= 2
+	for row := range s.rows {
+		for i := range s.rows[row] {
+			s.rows[row][i] >>= 1
+		}
+	}
+}
+
+// doorkeeper 一个简单的布隆过滤器 挡在sketch前面
+// 键第一次被访问时只记录在doorkeeper里 第二次访问才会进入sketch 这样大量只访问一次的键不会占用sketch的计数器
+type doorkeeper struct {
+	bits []uint64
+	mask uint64
+}
+
+// newDoorkeeper doorkeeper的构造函数 size为位数 会被向上取整为2的幂
+func newDoorkeeper(size int) *doorkeeper {
+	size = nextPowerOfTwo(size)
+	if size < 64 {
+		size = 64
+	}
+	return &doorkeeper{
+		bits: make([]uint64, size/64),
+		mask: uint64(size - 1),
+	}
+}
+
+// add 把键加入doorkeeper 返回键之前是否已经存在
+func (d *doorkeeper) add(hash uint64) (existed bool) {
+	existed = true
+	for _, bit := range d.positions(hash) {
+		if d.bits[bit/64]&(1<<(bit%64)) == 0 {
+			existed = false
+			d.bits[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return
+}
+
+// contains 键是否可能存在于doorkeeper中
+func (d *doorkeeper) contains(hash uint64) bool {
+	for _, bit := range d.positions(hash) {
+		if d.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// positions 键在位图中对应的两个位置
+func (d *doorkeeper) positions(hash uint64) [2]uint64 {
+	return [2]uint64{hash & d.mask, (hash >> 32) & d.mask}
+}
+
+// clear 清空doorkeeper 和sketch的衰减同时进行
+func (d *doorkeeper) clear() {
+	for i := range d.bits {
+		d.bits[i] = 0
+	}
+}
+
+// nextPowerOfTwo 返回不小于n的最小的2的幂
+func nextPowerOfTwo(n int) int {
+	power := 1
+	for power < n {
+		power <<= 1
+	}
+	return power
+}
@@ -0,0 +1,156 @@
+package tinylfu
+
+import "MisakaCache/src/misakacache/lru"
+
+/*
+W-TinyLFU算法 在缓存前面加一道基于访问频率的准入过滤
+
+普通的LRU对每一个新键都照单全收 大量只访问一次的键会把真正的热点缓存挤出去
+W-TinyLFU由三部分组成：
+1. 窗口LRU 占很小一部分内存 新键总是先进入窗口 让突发的新热点也有机会积累访问次数
+2. 主缓存 占绝大部分内存 这里使用LRU
+3. 频率估计器 Count-Min Sketch加上doorkeeper布隆过滤器 近似记录所有键（包括不在缓存里的键）的访问次数
+
+当窗口满了 被窗口淘汰的键成为候选者 主缓存下一个将被淘汰的键成为受害者
+只有候选者的估计访问次数比受害者高时 候选者才能进入主缓存并淘汰受害者 否则候选者直接被淘汰
+*/
+
+const (
+	windowPercent     = 1   // 窗口LRU占总内存的百分比
+	defaultEntryBytes = 128 // 估计的单条缓存大小 用于在只知道内存上限时推算sketch的大小
+	minCounters       = 1024
+)
+
+// TinyLFU 采用了W-TinyLFU策略的缓存类
+type TinyLFU struct {
+	window         *lru.LRU                          // 窗口LRU
+	main           *lru.LRU                          // 主缓存
+	mainBytes      int64                             // 主缓存允许使用的最大内存
+	sketch         *countMinSketch                   // 频率估计器
+	doorkeeper     *doorkeeper                       // 挡在sketch前面的布隆过滤器
+	OnEntryDeleted func(key string, value lru.Value) // 当缓存被淘汰或者未能通过准入时的回调函数
+}
+
+// NewTinyLFU TinyLFU的构造函数 按内存上限推算sketch的大小
+func NewTinyLFU(maxMemoryBytes int64, onEntryDeleted func(string, lru.Value)) *TinyLFU {
+	counters := int(maxMemoryBytes / defaultEntryBytes)
+	if counters < minCounters {
+		counters = minCounters
+	}
+	return NewTinyLFUWithCounters(maxMemoryBytes, counters, onEntryDeleted)
+}
+
+// NewTinyLFUWithCounters TinyLFU的构造函数 counters为预计的缓存条目数 决定了sketch和doorkeeper的大小
+func NewTinyLFUWithCounters(maxMemoryBytes int64, counters int, onEntryDeleted func(string, lru.Value)) (cache *TinyLFU) {
+	windowBytes := maxMemoryBytes * windowPercent / 100
+	cache = &TinyLFU{
+		mainBytes:      maxMemoryBytes - windowBytes,
+		sketch:         newCountMinSketch(counters),
+		doorkeeper:     newDoorkeeper(counters * 4),
+		OnEntryDeleted: onEntryDeleted,
+	}
+	cache.window = lru.NewLRU(windowBytes, cache.admit) // 窗口淘汰的键交给准入过滤
+	cache.main = lru.NewLRU(cache.mainBytes, cache.evicted)
+	return
+}
+
+// GetValue 在缓存里查找值 无论是否命中都会记录一次访问
+func (cache *TinyLFU) GetValue(key string) (value lru.Value, isOk bool) {
+	cache.record(key)
+	if value, isOk = cache.window.GetValue(key); isOk {
+		return
+	}
+	return cache.main.GetValue(key)
+}
+
+// SetValue 添加/修改缓存 新键先进入窗口
+func (cache *TinyLFU) SetValue(key string, value lru.Value) {
+	cache.record(key)
+	if _, isOk := cache.main.GetValue(key); isOk {
+		cache.main.SetValue(key, value)
+		return
+	}
+	cache.window.SetValue(key, value)
+}
+
+// RemoveValue 根据键删除一条缓存 返回该键是否存在 主动删除不会触发OnEntryDeleted回调
+func (cache *TinyLFU) RemoveValue(key string) bool {
+	return cache.window.RemoveValue(key) || cache.main.RemoveValue(key)
+}
+
+// Len 返回当前已缓存的键值对数量
+func (cache *TinyLFU) Len() int {
+	return cache.window.Len() + cache.main.Len()
+}
+
+// BytesUsed 返回当前已经使用的内存
+func (cache *TinyLFU) BytesUsed() int64 {
+	return cache.window.BytesUsed() + cache.main.BytesUsed()
+}
+
+// OnEvict 设置缓存被淘汰时的回调函数
+func (cache *TinyLFU) OnEvict(onEntryDeleted func(key string, value lru.Value)) {
+	cache.OnEntryDeleted = onEntryDeleted
+}
+
+// Frequency 返回键的估计访问次数 该方法不会记录访问
+func (cache *TinyLFU) Frequency(key string) int {
+	hash := hashKey(key)
+	frequency := cache.sketch.estimate(hash)
+	if cache.doorkeeper.contains(hash) {
+		frequency++
+	}
+	return frequency
+}
+
+// record 记录一次访问 第一次访问只进入doorkeeper
+func (cache *TinyLFU) record(key string) {
+	hash := hashKey(key)
+	if !cache.doorkeeper.add(hash) {
+		return
+	}
+	if cache.sketch.increment(hash) {
+		cache.doorkeeper.clear()
+	}
+}
+
+// admit 窗口LRU的淘汰回调 决定候选者能否进入主缓存
+func (cache *TinyLFU) admit(key string, value lru.Value) {
+	size := int64(len(key)) + int64(value.GetMemoryUsed())
+	if size > cache.mainBytes { // 放不进主缓存的键直接淘汰
+		cache.evicted(key, value)
+		return
+	}
+
+	// 先找出候选者需要挤掉的所有受害者 候选者比它们都热门时才真正淘汰 否则主缓存保持不变
+	candidateFrequency := cache.Frequency(key)
+	free := cache.mainBytes - cache.main.BytesUsed()
+	victims, rejected := 0, false
+	cache.main.WalkOldest(func(victimKey string, victimValue lru.Value) bool {
+		if free >= size {
+			return false
+		}
+		if candidateFrequency <= cache.Frequency(victimKey) { // 候选者不比受害者更热门 拒绝候选者
+			rejected = true
+			return false
+		}
+		free += int64(len(victimKey)) + int64(victimValue.GetMemoryUsed())
+		victims++
+		return true
+	})
+	if rejected {
+		cache.evicted(key, value)
+		return
+	}
+	for i := 0; i < victims; i++ {
+		cache.main.RemoveOldestCache() // 主缓存的淘汰回调是evicted 受害者同样会通知用户
+	}
+	cache.main.SetValue(key, value)
+}
+
+// evicted 缓存真正离开TinyLFU时调用用户的回调函数
+func (cache *TinyLFU) evicted(key string, value lru.Value) {
+	if cache.OnEntryDeleted != nil {
+		cache.OnEntryDeleted(key, value)
+	}
+}
@@ -0,0 +1,138 @@
+package misakacache
+
+import (
+	"MisakaCache/src/misakacache/lru"
+	"context"
+	"sync"
+	"time"
+)
+
+// TypedGroup 对Group的泛型封装 通过Codec自动完成编码和解码 调用方不再需要直接处理ByteView
+// 每次本地命中只解码一次 开启解码缓存后 同一份缓存数据只会被解码一次 之后直接返回解码好的对象
+type TypedGroup[T any] struct {
+	group *Group
+	codec Codec[T]
+
+	mu      sync.Mutex
+	decoded *lru.LRU // 解码缓存 为nil时不缓存解码结果
+}
+
+// decodedEntry 解码缓存中的一条记录 记录了解码时缓存数据的位置 缓存数据被更新后这条记录自动失效
+type decodedEntry[T any] struct {
+	source *byte // 缓存数据的首地址
+	length int
+	value  T
+}
+
+// GetMemoryUsed 解码后的对象大小无法得知 这里用编码后的大小近似
+func (entry decodedEntry[T]) GetMemoryUsed() int {
+	return entry.length
+}
+
+// matches 这条记录是否是从data解码而来的
+func (entry decodedEntry[T]) matches(data []byte) bool {
+	if len(data) != entry.length {
+		return false
+	}
+	return len(data) == 0 || &data[0] == entry.source
+}
+
+// NewTypedGroup TypedGroup的构造函数 decodedCacheBytes为解码缓存允许使用的最大内存 按编码后的大小计算 为0时不开启解码缓存
+// 开启解码缓存后 多个调用方会拿到同一个对象 T是指针、切片或map时调用方不能修改拿到的值
+func NewTypedGroup[T any](group *Group, codec Codec[T], decodedCacheBytes int64) *TypedGroup[T] {
+	typed := &TypedGroup[T]{group: group, codec: codec}
+	if decodedCacheBytes > 0 {
+		typed.decoded = lru.NewLRU(decodedCacheBytes, nil)
+	}
+	return typed
+}
+
+// TypedGetter 把返回T的加载函数包装成Getter 加载到的值通过codec编码后存入缓存
+func TypedGetter[T any](codec Codec[T], load func(ctx context.Context, key string) (T, error)) Getter {
+	return ContextGetterFunc(func(ctx context.Context, key string) ([]byte, error) {
+		value, err := load(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return codec.Encode(value)
+	})
+}
+
+// Group 返回被封装的Group
+func (tg *TypedGroup[T]) Group() *Group {
+	return tg.group
+}
+
+// Get 从缓存中获取值并解码
+func (tg *TypedGroup[T]) Get(ctx context.Context, key string) (value T, err error) {
+	view, err := tg.group.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	return tg.decode(key, view)
+}
+
+// GetMany 批量获取值并解码 返回的map中只包含存在并且解码成功的key 返回遇到的第一个错误
+func (tg *TypedGroup[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	views, err := tg.group.GetMany(ctx, keys)
+	values := make(map[string]T, len(views))
+	for key, view := range views {
+		value, decodeErr := tg.decode(key, view)
+		if decodeErr != nil {
+			if err == nil {
+				err = decodeErr
+			}
+			continue
+		}
+		values[key] = value
+	}
+	return values, err
+}
+
+// Set 编码后写入缓存 见Group.Set
+func (tg *TypedGroup[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := tg.codec.Encode(value)
+	if err != nil {
+		return err
+	}
+	return tg.group.Set(ctx, key, data, ttl)
+}
+
+// Remove 见Group.Remove
+func (tg *TypedGroup[T]) Remove(ctx context.Context, key string) error {
+	return tg.group.Remove(ctx, key)
+}
+
+// Invalidate 见Group.Invalidate
+func (tg *TypedGroup[T]) Invalidate(ctx context.Context, key string) error {
+	return tg.group.Invalidate(ctx, key)
+}
+
+// decode 解码缓存数据 开启解码缓存时先查找解码缓存
+func (tg *TypedGroup[T]) decode(key string, view ByteView) (T, error) {
+	if tg.decoded == nil {
+		return tg.codec.Decode(view.cacheBytes)
+	}
+
+	tg.mu.Lock()
+	if v, isOk := tg.decoded.GetValue(key); isOk {
+		if entry := v.(decodedEntry[T]); entry.matches(view.cacheBytes) {
+			tg.mu.Unlock()
+			return entry.value, nil
+		}
+	}
+	tg.mu.Unlock()
+
+	value, err := tg.codec.Decode(view.cacheBytes) // 解码可能比较慢 不持有锁
+	if err != nil {
+		return value, err
+	}
+	entry := decodedEntry[T]{length: len(view.cacheBytes), value: value}
+	if entry.length > 0 {
+		entry.source = &view.cacheBytes[0]
+	}
+	tg.mu.Lock()
+	tg.decoded.SetValue(key, entry)
+	tg.mu.Unlock()
+	return value, nil
+}